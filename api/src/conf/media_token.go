@@ -0,0 +1,114 @@
+package conf
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// MediaRoute is the media download route, reachable without the API key when the request
+	// carries a valid signed token.
+	MediaRoute = "/media/:id"
+	// DefaultPublicURL is the base URL of the API used in download links when PUBLIC_URL is not set.
+	DefaultPublicURL = "http://localhost:8080"
+)
+
+// MinMediaURLSecretLength is the shortest MEDIA_URL_SECRET accepted.
+const MinMediaURLSecretLength = 32
+
+// ErrInvalidMediaURLSecret is returned when MEDIA_URL_SECRET is too short.
+var ErrInvalidMediaURLSecret = errors.New("invalid media URL secret")
+
+var mediaTokenSecret []byte
+
+// InitMediaTokenSecret loads the key signing the media download tokens from MEDIA_URL_SECRET.
+// The key is dedicated to media URLs and must be kept across restarts: the URLs already handed out
+// in webhooks and Redis stop working when it changes, and rotating the API key does not affect them.
+// When the variable is not set, a random key is generated for this process and a warning is logged,
+// so the signed URLs only work until the next restart.
+func InitMediaTokenSecret() error {
+	secret := []byte(os.Getenv("MEDIA_URL_SECRET"))
+	switch {
+	case len(secret) == 0:
+		secret = make([]byte, MinMediaURLSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("%w: generate random secret: %v", ErrInvalidMediaURLSecret, err)
+		}
+		log.Println("[WARNING] MEDIA_URL_SECRET is not set: media download URLs are signed with a random key " +
+			"and stop working when the API restarts. Set it to a persistent value, e.g. the output of `openssl rand -hex 32`.")
+	case len(secret) < MinMediaURLSecretLength:
+		return fmt.Errorf("%w: MEDIA_URL_SECRET must have at least %d characters", ErrInvalidMediaURLSecret, MinMediaURLSecretLength)
+	}
+
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	mediaTokenSecret = secret
+	return nil
+}
+
+// SignMediaToken returns the HMAC-SHA256 token allowing the download of a media until it expires.
+func SignMediaToken(mediaID string, expires time.Time) string {
+	tokenMutex.RLock()
+	defer tokenMutex.RUnlock()
+
+	mac := hmac.New(sha256.New, mediaTokenSecret)
+	mac.Write([]byte(mediaID + ":" + strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidMediaToken reports whether the token signs the media ID and expiration (Unix seconds),
+// and the expiration has not passed.
+func ValidMediaToken(mediaID string, expires string, token string) bool {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresUnix {
+		return false
+	}
+
+	expected := SignMediaToken(mediaID, time.Unix(expiresUnix, 0))
+	return hmac.Equal([]byte(expected), []byte(token))
+}
+
+// SignedMediaURL returns the download URL of a media served by the API, signed until it expires.
+// The base URL comes from PUBLIC_URL.
+func SignedMediaURL(mediaID int64, expires time.Time) string {
+	baseURL := os.Getenv("PUBLIC_URL")
+	if baseURL == "" {
+		baseURL = DefaultPublicURL
+	}
+
+	id := strconv.FormatInt(mediaID, 10)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("token", SignMediaToken(id, expires))
+	path := strings.Replace(MediaRoute, ":id", id, 1)
+	return fmt.Sprintf("%s%s?%s", strings.TrimSuffix(baseURL, "/"), path, query.Encode())
+}
+
+// MediaTokenMiddleware is a Gin middleware protecting the media download route, which TokenMiddleware
+// lets through. Requests are accepted with the `X-Api-Key` header or with a valid signed token in the
+// `expires` and `token` query parameters; others receive a 401 response.
+func MediaTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if validAPIKey(c.Request.Header.Get("X-Api-Key")) ||
+			ValidMediaToken(c.Param("id"), c.Query("expires"), c.Query("token")) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,127 @@
+package conf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testMediaURLSecret = "0123456789abcdef0123456789abcdef"
+
+// newMediaTestRouter returns a router protected like the application one, with a media route and an API route.
+func newMediaTestRouter(t *testing.T) *gin.Engine {
+	t.Setenv("API_KEY_TOKEN", "api-key")
+	t.Setenv("MEDIA_URL_SECRET", testMediaURLSecret)
+	t.Setenv("PUBLIC_URL", "http://api.test")
+	InitToken()
+	if err := InitMediaTokenSecret(); err != nil {
+		t.Fatalf("InitMediaTokenSecret: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(TokenMiddleware())
+	r.GET(MediaRoute, MediaTokenMiddleware(), func(c *gin.Context) { c.String(http.StatusOK, "media") })
+	r.GET("/device", func(c *gin.Context) { c.String(http.StatusOK, "devices") })
+	return r
+}
+
+// requestPath returns the path and query of a signed URL.
+func requestPath(t *testing.T, signedURL string) string {
+	parsed, err := url.Parse(signedURL)
+	if err != nil {
+		t.Fatalf("invalid signed URL %q: %v", signedURL, err)
+	}
+	return parsed.RequestURI()
+}
+
+func TestMediaTokenMiddleware(t *testing.T) {
+	r := newMediaTestRouter(t)
+	valid := requestPath(t, SignedMediaURL(42, time.Now().Add(time.Hour)))
+	expired := requestPath(t, SignedMediaURL(42, time.Now().Add(-time.Minute)))
+	tampered := valid[:len(valid)-1] + "a"
+	if tampered == valid {
+		tampered = valid[:len(valid)-1] + "b"
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		apiKey string
+		want   int
+	}{
+		{"signed URL", valid, "", http.StatusOK},
+		{"API key", "/media/42", "api-key", http.StatusOK},
+		{"no credentials", "/media/42", "", http.StatusUnauthorized},
+		{"expired token", expired, "", http.StatusUnauthorized},
+		{"token of another media", strings.Replace(valid, "/media/42", "/media/43", 1), "", http.StatusUnauthorized},
+		{"tampered token", tampered, "", http.StatusUnauthorized},
+		{"tampered expiration", strings.Replace(valid, "expires=", "expires=9", 1), "", http.StatusUnauthorized},
+		{"token on another route", strings.Replace(valid, "/media/42", "/device", 1), "", http.StatusUnauthorized},
+		{"wrong API key", "/media/42", "other-key", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-Api-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignedMediaURLDoesNotDependOnAPIKey(t *testing.T) {
+	newMediaTestRouter(t)
+	expires := time.Now().Add(time.Hour)
+	before := SignMediaToken("42", expires)
+
+	t.Setenv("API_KEY_TOKEN", "rotated-api-key")
+	InitToken()
+	if after := SignMediaToken("42", expires); after != before {
+		t.Errorf("rotating the API key changed the media token from %s to %s", before, after)
+	}
+}
+
+func TestInitMediaTokenSecretRejectsShortSecret(t *testing.T) {
+	t.Setenv("MEDIA_URL_SECRET", "too-short")
+	if err := InitMediaTokenSecret(); !errors.Is(err, ErrInvalidMediaURLSecret) {
+		t.Errorf("InitMediaTokenSecret = %v, want ErrInvalidMediaURLSecret", err)
+	}
+}
+
+func TestInitMediaTokenSecretFallsBackToRandomKey(t *testing.T) {
+	t.Setenv("MEDIA_URL_SECRET", "")
+	expires := time.Now().Add(time.Hour)
+
+	if err := InitMediaTokenSecret(); err != nil {
+		t.Fatalf("InitMediaTokenSecret: %v", err)
+	}
+	first := SignMediaToken("42", expires)
+
+	if err := InitMediaTokenSecret(); err != nil {
+		t.Fatalf("InitMediaTokenSecret: %v", err)
+	}
+	if second := SignMediaToken("42", expires); second == first {
+		t.Error("every process must generate its own random key")
+	}
+
+	t.Setenv("API_KEY_TOKEN", "api-key")
+	InitToken()
+	if ValidMediaToken("42", strconv.FormatInt(expires.Unix(), 10), "api-key") {
+		t.Error("the API key must not sign media tokens")
+	}
+}
@@ -32,17 +32,28 @@ func InitToken() {
 	}
 }
 
+// validAPIKey reports whether the key matches the token initialized by InitToken.
+func validAPIKey(key string) bool {
+	// Lock the token for read access with a read lock.
+	tokenMutex.RLock()
+	defer tokenMutex.RUnlock()
+
+	return key == token
+}
+
 // TokenMiddleware is a Gin middleware that validates incoming requests using the `X-Api-Key` header.
 // It checks if the provided token matches the one initialized by InitToken.
-// Unauthorized requests receive a 401 response.
+// Unauthorized requests receive a 401 response. Media downloads are left to MediaTokenMiddleware,
+// as they can also be authorized by a signed URL.
 func TokenMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Lock the token for read access with a read lock.
-		tokenMutex.RLock()
-		defer tokenMutex.RUnlock()
+		if c.FullPath() == MediaRoute {
+			c.Next()
+			return
+		}
 
 		// Check if the request contains the correct API token.
-		if c.Request.Header.Get("X-Api-Key") != token {
+		if !validAPIKey(c.Request.Header.Get("X-Api-Key")) {
 			// Respond with 401 Unauthorized if the token is invalid.
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
@@ -62,7 +73,7 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-Api-Key, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		// Respond to preflight OPTIONS requests and terminate them early.
 		if c.Request.Method == "OPTIONS" {
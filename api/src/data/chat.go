@@ -0,0 +1,58 @@
+package data
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Chat represents a chat of the device in the message history, with its latest message and unread count.
+type Chat struct {
+	ChatJID     string         `json:"chat_jid"`     // JID of the chat (user or group)
+	IsGroup     bool           `json:"is_group"`     // Whether the chat is a group
+	UnreadCount int            `json:"unread_count"` // Number of incoming messages not marked as read
+	LastMessage *StoredMessage `json:"last_message"` // Latest message exchanged in the chat
+}
+
+// MessageFilter holds the filters and pagination used to read the message history of a chat.
+type MessageFilter struct {
+	MediaType string         // Only messages of this media type (TEXT, IMAGE, ...), empty for all
+	IsFromMe  *bool          // Only sent (true) or received (false) messages, nil for both
+	Before    *MessageCursor // Only messages older than the cursor, nil to start from the latest
+	Limit     int            // Maximum number of messages returned
+}
+
+// MessageCursor points at a message in the history of a chat. The ID breaks ties between messages
+// sharing the same timestamp, so pages never skip or repeat messages.
+type MessageCursor struct {
+	Timestamp time.Time // Timestamp of the message
+	ID        int64     // Database ID of the message
+}
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NewMessageCursor returns the cursor pointing at the given message.
+func NewMessageCursor(message Message) *MessageCursor {
+	return &MessageCursor{Timestamp: message.Timestamp, ID: message.ID}
+}
+
+// Encode returns the opaque representation of the cursor used by the API.
+func (c *MessageCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Timestamp.UnixNano(), c.ID)))
+}
+
+// ParseMessageCursor decodes a cursor previously returned by Encode.
+func ParseMessageCursor(value string) (*MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var nanos, id int64
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return &MessageCursor{Timestamp: time.Unix(0, nanos), ID: id}, nil
+}
@@ -0,0 +1,115 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// Contact represents a contact card (vCard) shared in a message.
+type Contact struct {
+	Name         string   `json:"name"`                   // Display name of the contact
+	Organization string   `json:"organization,omitempty"` // Company or organization
+	Phones       []string `json:"phones,omitempty"`       // Phone numbers
+	Emails       []string `json:"emails,omitempty"`       // E-mail addresses
+}
+
+// vCardEscaper escapes the characters that have a special meaning in vCard values.
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+
+// vCardUnescaper reverts the escaping applied to vCard values.
+var vCardUnescaper = strings.NewReplacer(`\\`, `\`, `\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n")
+
+// BuildVCard renders the contact as a vCard 3.0 string. Phone numbers carry the "waid" parameter
+// so WhatsApp shows the "Message" button for numbers registered on WhatsApp.
+func BuildVCard(contact Contact) string {
+	var sb strings.Builder
+	name := vCardEscaper.Replace(contact.Name)
+
+	sb.WriteString("BEGIN:VCARD\nVERSION:3.0\n")
+	sb.WriteString(fmt.Sprintf("N:;%s;;;\nFN:%s\n", name, name))
+	if contact.Organization != "" {
+		sb.WriteString(fmt.Sprintf("ORG:%s\n", vCardEscaper.Replace(contact.Organization)))
+	}
+	for _, phone := range contact.Phones {
+		waID := strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, phone)
+		sb.WriteString(fmt.Sprintf("TEL;type=CELL;type=VOICE;waid=%s:%s\n", waID, vCardEscaper.Replace(phone)))
+	}
+	for _, email := range contact.Emails {
+		sb.WriteString(fmt.Sprintf("EMAIL;type=INTERNET:%s\n", vCardEscaper.Replace(email)))
+	}
+	sb.WriteString("END:VCARD")
+
+	return sb.String()
+}
+
+// ParseVCard extracts the name, organization, phones and e-mails of a vCard.
+// The display name falls back to the given name when the card has no FN/N property.
+func ParseVCard(vcard string, displayName string) Contact {
+	contact := Contact{Name: displayName}
+
+	// Unfold continuation lines (lines starting with a space or tab belong to the previous one).
+	unfolded := strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(vcard)
+
+	for _, line := range strings.Split(unfolded, "\n") {
+		property, value, found := strings.Cut(strings.TrimRight(line, "\r"), ":")
+		if !found {
+			continue
+		}
+
+		// Drop parameters and group prefixes, e.g. "item1.TEL;type=CELL" becomes "TEL".
+		name, _, _ := strings.Cut(property, ";")
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			name = name[idx+1:]
+		}
+
+		switch strings.ToUpper(name) {
+		case "FN":
+			contact.Name = vCardUnescaper.Replace(value)
+		case "N":
+			if contact.Name == "" {
+				parts := strings.Split(value, ";")
+				contact.Name = strings.TrimSpace(strings.Join(reverseNonEmpty(parts[:min(len(parts), 2)]), " "))
+			}
+		case "ORG":
+			contact.Organization = strings.TrimRight(vCardUnescaper.Replace(value), ";")
+		case "TEL":
+			contact.Phones = append(contact.Phones, vCardUnescaper.Replace(value))
+		case "EMAIL":
+			contact.Emails = append(contact.Emails, vCardUnescaper.Replace(value))
+		}
+	}
+
+	return contact
+}
+
+// reverseNonEmpty returns the non-empty values in reverse order ("Family;Given" becomes "Given Family").
+func reverseNonEmpty(values []string) []string {
+	var result []string
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] != "" {
+			result = append(result, values[i])
+		}
+	}
+	return result
+}
+
+// contactsFromMessage parses the contact cards of a single-contact or multi-contact message.
+func contactsFromMessage(message *waE2E.Message) []Contact {
+	if contactMessage := message.GetContactMessage(); contactMessage != nil {
+		return []Contact{ParseVCard(contactMessage.GetVcard(), contactMessage.GetDisplayName())}
+	}
+
+	var contacts []Contact
+	for _, contactMessage := range message.GetContactsArrayMessage().GetContacts() {
+		contacts = append(contacts, ParseVCard(contactMessage.GetVcard(), contactMessage.GetDisplayName()))
+	}
+	return contacts
+}
@@ -0,0 +1,161 @@
+package data
+
+import (
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Event types carried by GroupEvent, one per kind of group change.
+const (
+	EventTypeGroupJoined             = "GROUP_JOINED"              // The device joined or created a group
+	EventTypeGroupParticipantsJoined = "GROUP_PARTICIPANTS_JOINED" // Participants joined or were added to the group
+	EventTypeGroupParticipantsLeft   = "GROUP_PARTICIPANTS_LEFT"   // Participants left or were removed from the group
+	EventTypeGroupAdminsPromoted     = "GROUP_ADMINS_PROMOTED"     // Participants were promoted to admins
+	EventTypeGroupAdminsDemoted      = "GROUP_ADMINS_DEMOTED"      // Admins were demoted to regular participants
+	EventTypeGroupSubject            = "GROUP_SUBJECT"             // The group name changed
+	EventTypeGroupDescription        = "GROUP_DESCRIPTION"         // The group description changed or was removed
+	EventTypeGroupSettings           = "GROUP_SETTINGS"            // Group settings (locked, announce, disappearing messages, approval) changed
+	EventTypeGroupInviteLink         = "GROUP_INVITE_LINK"         // The invite link was reset
+	EventTypeGroupDeleted            = "GROUP_DELETED"             // The group was deleted
+)
+
+// GroupSettings holds the group settings changed by a GROUP_SETTINGS event. Unchanged settings are nil.
+type GroupSettings struct {
+	IsLocked             *bool   `json:"is_locked,omitempty"`              // Only admins can edit the group info
+	IsAnnounce           *bool   `json:"is_announce,omitempty"`            // Only admins can send messages
+	DisappearingTimer    *uint32 `json:"disappearing_timer,omitempty"`     // Disappearing messages timer in seconds (0 disables it)
+	JoinApprovalRequired *bool   `json:"join_approval_required,omitempty"` // Admins must approve new participants
+}
+
+// GroupEvent represents a change in a group the device participates in, delivered like messages through
+// the webhook and Redis.
+type GroupEvent struct {
+	EventType    string         `json:"event_type"`             // Kind of change (GROUP_JOINED, GROUP_PARTICIPANTS_JOINED, ...)
+	GroupJID     string         `json:"group_jid"`              // JID of the group
+	ActorJID     string         `json:"actor_jid,omitempty"`    // JID of the user who made the change, when known
+	Participants []string       `json:"participants,omitempty"` // JIDs of the affected participants
+	Reason       string         `json:"reason,omitempty"`       // Why participants joined (e.g. "invite")
+	Name         string         `json:"name,omitempty"`         // Group name (new name for GROUP_SUBJECT)
+	Description  string         `json:"description,omitempty"`  // New group description
+	Settings     *GroupSettings `json:"settings,omitempty"`     // Changed settings
+	InviteLink   string         `json:"invite_link,omitempty"`  // New invite link
+	Timestamp    time.Time      `json:"timestamp"`              // Timestamp of the change
+}
+
+// ConvertGroupInfoEvent normalises a WhatsApp group info event into one GroupEvent per kind of change it carries.
+func ConvertGroupInfoEvent(evt *events.GroupInfo) []GroupEvent {
+	base := GroupEvent{
+		GroupJID:  evt.JID.String(),
+		Timestamp: evt.Timestamp,
+	}
+	if evt.Sender != nil {
+		base.ActorJID = evt.Sender.ToNonAD().String()
+	}
+
+	var groupEvents []GroupEvent
+	add := func(eventType string, fill func(*GroupEvent)) {
+		groupEvent := base
+		groupEvent.EventType = eventType
+		fill(&groupEvent)
+		groupEvents = append(groupEvents, groupEvent)
+	}
+
+	// Participant changes
+	participantChanges := []struct {
+		eventType string
+		jids      []types.JID
+	}{
+		{EventTypeGroupParticipantsJoined, evt.Join},
+		{EventTypeGroupParticipantsLeft, evt.Leave},
+		{EventTypeGroupAdminsPromoted, evt.Promote},
+		{EventTypeGroupAdminsDemoted, evt.Demote},
+	}
+	for _, change := range participantChanges {
+		if len(change.jids) == 0 {
+			continue
+		}
+		add(change.eventType, func(groupEvent *GroupEvent) {
+			groupEvent.Participants = jidsToStrings(change.jids)
+			if change.eventType == EventTypeGroupParticipantsJoined {
+				groupEvent.Reason = evt.JoinReason
+			}
+		})
+	}
+
+	// Subject and description changes
+	if evt.Name != nil {
+		add(EventTypeGroupSubject, func(groupEvent *GroupEvent) {
+			groupEvent.Name = evt.Name.Name
+		})
+	}
+	if evt.Topic != nil {
+		add(EventTypeGroupDescription, func(groupEvent *GroupEvent) {
+			if !evt.Topic.TopicDeleted {
+				groupEvent.Description = evt.Topic.Topic
+			}
+		})
+	}
+
+	// Setting changes are grouped in a single event
+	if evt.Locked != nil || evt.Announce != nil || evt.Ephemeral != nil || evt.MembershipApprovalMode != nil {
+		add(EventTypeGroupSettings, func(groupEvent *GroupEvent) {
+			settings := &GroupSettings{}
+			if evt.Locked != nil {
+				settings.IsLocked = &evt.Locked.IsLocked
+			}
+			if evt.Announce != nil {
+				settings.IsAnnounce = &evt.Announce.IsAnnounce
+			}
+			if evt.Ephemeral != nil {
+				settings.DisappearingTimer = &evt.Ephemeral.DisappearingTimer
+			}
+			if evt.MembershipApprovalMode != nil {
+				settings.JoinApprovalRequired = &evt.MembershipApprovalMode.IsJoinApprovalRequired
+			}
+			groupEvent.Settings = settings
+		})
+	}
+
+	if evt.NewInviteLink != nil {
+		add(EventTypeGroupInviteLink, func(groupEvent *GroupEvent) {
+			groupEvent.InviteLink = *evt.NewInviteLink
+		})
+	}
+	if evt.Delete != nil && evt.Delete.Deleted {
+		add(EventTypeGroupDeleted, func(groupEvent *GroupEvent) {
+			groupEvent.Reason = evt.Delete.DeleteReason
+		})
+	}
+
+	return groupEvents
+}
+
+// ConvertJoinedGroupEvent converts the event emitted when the device joins or creates a group.
+func ConvertJoinedGroupEvent(evt *events.JoinedGroup) GroupEvent {
+	participants := make([]types.JID, len(evt.Participants))
+	for i, participant := range evt.Participants {
+		participants[i] = participant.JID
+	}
+
+	return GroupEvent{
+		EventType:    EventTypeGroupJoined,
+		GroupJID:     evt.JID.String(),
+		ActorJID:     evt.OwnerJID.String(),
+		Participants: jidsToStrings(participants),
+		Reason:       evt.Reason,
+		Name:         evt.Name,
+		Description:  evt.Topic,
+		Timestamp:    evt.GroupCreated,
+	}
+}
+
+// jidsToStrings converts a list of JIDs to their string representation.
+func jidsToStrings(jids []types.JID) []string {
+	result := make([]string, len(jids))
+	for i, jid := range jids {
+		result[i] = jid.String()
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package data
+
+import "go.mau.fi/whatsmeow/proto/waE2E"
+
+// Location represents the structured coordinates of a location or live-location message.
+type Location struct {
+	Latitude       float64 `json:"latitude"`                  // Latitude in decimal degrees
+	Longitude      float64 `json:"longitude"`                 // Longitude in decimal degrees
+	Name           string  `json:"name,omitempty"`            // Name of the place (static locations only)
+	Address        string  `json:"address,omitempty"`         // Address of the place (static locations only)
+	IsLive         bool    `json:"is_live"`                   // Whether the location is being shared live
+	AccuracyMeters uint32  `json:"accuracy_meters,omitempty"` // Accuracy of the coordinates in meters
+	SpeedMps       float32 `json:"speed_mps,omitempty"`       // Speed in meters per second (live locations)
+}
+
+// locationFromMessage extracts the location of a static location message.
+func locationFromMessage(message *waE2E.LocationMessage) *Location {
+	return &Location{
+		Latitude:       message.GetDegreesLatitude(),
+		Longitude:      message.GetDegreesLongitude(),
+		Name:           message.GetName(),
+		Address:        message.GetAddress(),
+		IsLive:         message.GetIsLive(),
+		AccuracyMeters: message.GetAccuracyInMeters(),
+		SpeedMps:       message.GetSpeedInMps(),
+	}
+}
+
+// locationFromLiveMessage extracts the current position of a live-location message.
+func locationFromLiveMessage(message *waE2E.LiveLocationMessage) *Location {
+	return &Location{
+		Latitude:       message.GetDegreesLatitude(),
+		Longitude:      message.GetDegreesLongitude(),
+		IsLive:         true,
+		AccuracyMeters: message.GetAccuracyInMeters(),
+		SpeedMps:       message.GetSpeedInMps(),
+	}
+}
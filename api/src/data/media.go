@@ -0,0 +1,39 @@
+package data
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Media represents a media content downloaded from a message and kept in the media store.
+// The content is identified by its SHA-256 digest, so the same file is only stored once.
+type Media struct {
+	bun.BaseModel `bun:"table:media,alias:mda"` // Specifies the table name and alias
+	ID            int64                         `json:"id" bun:"id,pk,autoincrement"`          // Primary key, auto-incremented
+	SHA256        string                        `json:"sha256" bun:"sha256,notnull,unique"`    // Hex SHA-256 digest of the content
+	Size          int64                         `json:"size" bun:"size,notnull"`               // Size of the content in bytes
+	MimeType      string                        `json:"mime_type" bun:"mime_type,notnull"`     // Mime type of the content
+	StorageKey    string                        `json:"storage_key" bun:"storage_key,notnull"` // Key of the content in the media store
+	CreatedAt     time.Time                     `json:"created_at" bun:"created_at,notnull"`   // Timestamp when the media was stored
+}
+
+// MediaInfo describes the media of a message sent to webhooks and Redis in place of the raw content.
+type MediaInfo struct {
+	ID           int64      `json:"id"`                       // ID of the media
+	Size         int64      `json:"size"`                     // Size of the content in bytes
+	SHA256       string     `json:"sha256"`                   // Hex SHA-256 digest of the content
+	MimeType     string     `json:"mime_type"`                // Mime type of the content
+	URL          string     `json:"url,omitempty"`            // Signed download URL of the content
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"` // Time after which the download URL stops working
+}
+
+// NewMediaInfo returns the description of a stored media, without download URL.
+func NewMediaInfo(media Media) *MediaInfo {
+	return &MediaInfo{
+		ID:       media.ID,
+		Size:     media.Size,
+		SHA256:   media.SHA256,
+		MimeType: media.MimeType,
+	}
+}
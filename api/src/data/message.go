@@ -0,0 +1,119 @@
+package data
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Message represents a message received or sent by a device, as stored in the message history.
+// It is derived from StoredMessage; media content is not persisted.
+type Message struct {
+	bun.BaseModel   `bun:"table:message,alias:msg"` // Specifies the table name and alias
+	ID              int64                           `json:"id" bun:"id,pk,autoincrement"`                                 // Primary key, auto-incremented
+	DeviceID        int                             `json:"device_id" bun:"device_id,notnull"`                            // Foreign key to the device table
+	MessageID       string                          `json:"message_id" bun:"message_id,notnull"`                          // WhatsApp ID of the message
+	ChatJID         string                          `json:"chat_jid" bun:"chat_jid,notnull"`                              // JID of the chat (user or group)
+	SenderJID       string                          `json:"sender_jid" bun:"sender_jid,notnull"`                          // JID of the message author
+	RecipientID     string                          `json:"recipient_id" bun:"recipient_id,notnull"`                      // WhatsApp ID (user part) of the chat
+	PushName        string                          `json:"push_name" bun:"push_name,notnull"`                            // Display name of the author
+	EventType       string                          `json:"event_type" bun:"event_type,notnull"`                          // MESSAGE, MESSAGE_EDIT, MESSAGE_REVOKE or POLL_VOTE
+	MediaType       string                          `json:"media_type" bun:"media_type,notnull"`                          // Type of message (TEXT, IMAGE, VIDEO, etc.)
+	Text            string                          `json:"text" bun:"text,notnull"`                                      // Text or caption of the message
+	ContentMimeType string                          `json:"content_mime_type" bun:"content_mime_type,notnull"`            // Mime type of the message content
+	IsFromMe        bool                            `json:"is_from_me" bun:"is_from_me,notnull"`                          // Whether the message was sent by the device
+	IsFromGroup     bool                            `json:"is_from_group" bun:"is_from_group,notnull"`                    // Whether the message belongs to a group chat
+	TargetMessageID string                          `json:"target_message_id,omitempty" bun:"target_message_id,nullzero"` // ID of the message a reaction, edit, revoke or vote refers to
+	MentionedMe     bool                            `json:"mentioned_me" bun:"mentioned_me,notnull"`                      // Whether the device was mentioned
+	MediaID         int64                           `json:"media_id,omitempty" bun:"media_id,nullzero"`                   // Foreign key to the media table, if the message has media
+	Metadata        *MessageMetadata                `json:"metadata,omitempty" bun:"metadata,type:jsonb"`                 // Structured content of the message
+	SearchLanguage  string                          `json:"-" bun:"search_language,notnull"`                              // Text search configuration (language) used to index the text
+	Timestamp       time.Time                       `json:"timestamp" bun:"timestamp,notnull"`                            // Timestamp of the message
+	EditedAt        *time.Time                      `json:"edited_at,omitempty" bun:"edited_at"`                          // Timestamp of the latest edit
+	RevokedAt       *time.Time                      `json:"revoked_at,omitempty" bun:"revoked_at"`                        // Timestamp when the message was deleted for everyone
+	ReadAt          *time.Time                      `json:"read_at,omitempty" bun:"read_at"`                              // Timestamp when the incoming message was marked as read
+	CreatedAt       time.Time                       `json:"created_at" bun:"created_at,notnull"`                          // Timestamp when the record was created
+}
+
+// MessageMetadata holds the structured content of a message that does not fit in plain columns.
+type MessageMetadata struct {
+	Location      *Location        `json:"location,omitempty"`       // Coordinates of location messages
+	Contacts      []Contact        `json:"contacts,omitempty"`       // Contact cards of contact messages
+	Poll          *PollContent     `json:"poll,omitempty"`           // Question and options of poll messages
+	PollVote      *PollVoteContent `json:"poll_vote,omitempty"`      // Decrypted vote of poll vote events
+	MentionedJIDs []string         `json:"mentioned_jids,omitempty"` // JIDs of the users mentioned in the text
+	Media         *MediaInfo       `json:"media,omitempty"`          // Size, digest and mime type of the stored media
+}
+
+// NewMessage converts a stored message of the device into its message history record.
+func NewMessage(deviceID int, stored *StoredMessage) *Message {
+	message := &Message{
+		DeviceID:        deviceID,
+		MessageID:       stored.MessageID,
+		ChatJID:         stored.ChatJID,
+		SenderJID:       stored.SenderJID,
+		RecipientID:     stored.RecipientID,
+		PushName:        stored.RecipientName,
+		EventType:       stored.EventType,
+		MediaType:       stored.MediaType,
+		Text:            stored.Text,
+		ContentMimeType: stored.ContentMimeType,
+		IsFromMe:        stored.IsFromMe,
+		IsFromGroup:     stored.IsFromGroup,
+		TargetMessageID: stored.TargetMessageID,
+		MentionedMe:     stored.MentionedMe,
+		Timestamp:       stored.Timestamp,
+		CreatedAt:       time.Now(),
+	}
+
+	var media *MediaInfo
+	if stored.Media != nil {
+		// The download URL expires, so only the description of the media is kept.
+		message.MediaID = stored.Media.ID
+		info := *stored.Media
+		info.URL, info.URLExpiresAt = "", nil
+		media = &info
+	}
+
+	if stored.Location != nil || len(stored.Contacts) > 0 || stored.Poll != nil || stored.PollVote != nil || len(stored.MentionedJIDs) > 0 || media != nil {
+		message.Metadata = &MessageMetadata{
+			Location:      stored.Location,
+			Contacts:      stored.Contacts,
+			Poll:          stored.Poll,
+			PollVote:      stored.PollVote,
+			MentionedJIDs: stored.MentionedJIDs,
+			Media:         media,
+		}
+	}
+	return message
+}
+
+// ToStoredMessage converts the message history record back to a StoredMessage (without media content).
+func (m *Message) ToStoredMessage() *StoredMessage {
+	stored := &StoredMessage{
+		EventType:       m.EventType,
+		MessageID:       m.MessageID,
+		IsFromMe:        m.IsFromMe,
+		IsFromGroup:     m.IsFromGroup,
+		MediaType:       m.MediaType,
+		Text:            m.Text,
+		ContentMimeType: m.ContentMimeType,
+		RecipientID:     m.RecipientID,
+		RecipientName:   m.PushName,
+		ChatJID:         m.ChatJID,
+		SenderJID:       m.SenderJID,
+		TargetMessageID: m.TargetMessageID,
+		MentionedMe:     m.MentionedMe,
+		Timestamp:       m.Timestamp,
+	}
+
+	if m.Metadata != nil {
+		stored.Location = m.Metadata.Location
+		stored.Contacts = m.Metadata.Contacts
+		stored.Poll = m.Metadata.Poll
+		stored.PollVote = m.Metadata.PollVote
+		stored.MentionedJIDs = m.Metadata.MentionedJIDs
+		stored.Media = m.Metadata.Media
+	}
+	return stored
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Delivery statuses of sent messages, in the order they are reached.
+const (
+	MessageStatusSent      = "SENT"      // The message was accepted by the WhatsApp server
+	MessageStatusDelivered = "DELIVERED" // The message reached the recipient's device
+	MessageStatusRead      = "READ"      // The recipient opened the message
+	MessageStatusPlayed    = "PLAYED"    // The recipient played the voice note or video
+)
+
+// EventTypeMessageStatus is the event type of the webhooks sent when a message status changes.
+const EventTypeMessageStatus = "MESSAGE_STATUS"
+
+// messageStatusRanks orders the statuses so a status never goes backwards.
+var messageStatusRanks = map[string]int{
+	MessageStatusSent:      1,
+	MessageStatusDelivered: 2,
+	MessageStatusRead:      3,
+	MessageStatusPlayed:    4,
+}
+
+// MessageStatus represents the latest delivery status of a sent message for one recipient.
+type MessageStatus struct {
+	bun.BaseModel `bun:"table:message_status,alias:msg_st"` // Specifies the table name and alias
+	ID            int                                       `json:"-" bun:"id,pk,autoincrement"`               // Primary key, auto-incremented
+	DeviceID      int                                       `json:"device_id" bun:"device_id,notnull"`         // Foreign key to the device table
+	MessageID     string                                    `json:"message_id" bun:"message_id,notnull"`       // WhatsApp ID of the sent message
+	ChatJID       string                                    `json:"chat_jid" bun:"chat_jid,notnull"`           // JID of the chat the message was sent to
+	RecipientJID  string                                    `json:"recipient_jid" bun:"recipient_jid,notnull"` // JID of the recipient that reported the status
+	Status        string                                    `json:"status" bun:"status,notnull"`               // Latest status (SENT, DELIVERED, READ or PLAYED)
+	StatusRank    int                                       `json:"-" bun:"status_rank,notnull"`               // Order of the status
+	SentAt        *time.Time                                `json:"sent_at,omitempty" bun:"sent_at"`           // Timestamp when the message was sent
+	DeliveredAt   *time.Time                                `json:"delivered_at,omitempty" bun:"delivered_at"` // Timestamp when the message was delivered
+	ReadAt        *time.Time                                `json:"read_at,omitempty" bun:"read_at"`           // Timestamp when the message was read
+	PlayedAt      *time.Time                                `json:"played_at,omitempty" bun:"played_at"`       // Timestamp when the message was played
+	UpdatedAt     time.Time                                 `json:"updated_at" bun:"updated_at,notnull"`       // Timestamp of the latest status change
+}
+
+// NewMessageStatus builds the status row of a message for a recipient. Reaching a status implies the
+// previous ones, so their timestamps are filled too when not known yet.
+func NewMessageStatus(deviceID int, messageID string, chatJID string, recipientJID string, status string, timestamp time.Time) *MessageStatus {
+	rank := messageStatusRanks[status]
+	messageStatus := &MessageStatus{
+		DeviceID:     deviceID,
+		MessageID:    messageID,
+		ChatJID:      chatJID,
+		RecipientJID: recipientJID,
+		Status:       status,
+		StatusRank:   rank,
+		UpdatedAt:    timestamp,
+	}
+
+	stages := []**time.Time{&messageStatus.SentAt, &messageStatus.DeliveredAt, &messageStatus.ReadAt, &messageStatus.PlayedAt}
+	for i := 0; i < rank && i < len(stages); i++ {
+		*stages[i] = &timestamp
+	}
+	return messageStatus
+}
+
+// MessageStatusEvent is delivered through the webhook when a sent message reaches a new status.
+type MessageStatusEvent struct {
+	EventType    string    `json:"event_type"`    // Always MESSAGE_STATUS
+	MessageID    string    `json:"message_id"`    // WhatsApp ID of the sent message
+	ChatJID      string    `json:"chat_jid"`      // JID of the chat the message was sent to
+	RecipientJID string    `json:"recipient_jid"` // JID of the recipient that reported the status
+	Status       string    `json:"status"`        // New status (DELIVERED, READ or PLAYED)
+	Timestamp    time.Time `json:"timestamp"`     // Timestamp of the receipt
+}
+
+// MessageStatusResponse aggregates the status of a sent message across its recipients.
+type MessageStatusResponse struct {
+	MessageID  string          `json:"message_id"` // WhatsApp ID of the sent message
+	ChatJID    string          `json:"chat_jid"`   // JID of the chat the message was sent to
+	Status     string          `json:"status"`     // Most advanced status reported by any recipient
+	Recipients []MessageStatus `json:"recipients"` // Status per recipient
+}
@@ -0,0 +1,99 @@
+package data
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// Poll represents a poll sent or received by a device, kept to tally its votes.
+type Poll struct {
+	bun.BaseModel   `bun:"table:poll,alias:pll"` // Specifies the table name and alias
+	ID              int                          `json:"id" bun:"id,pk,autoincrement"`            // Primary key, auto-incremented
+	DeviceID        int                          `json:"device_id" bun:"device_id,notnull"`       // Foreign key to the device table
+	MessageID       string                       `json:"message_id" bun:"message_id,notnull"`     // WhatsApp ID of the poll creation message
+	ChatJID         string                       `json:"chat_jid" bun:"chat_jid,notnull"`         // JID of the chat where the poll was sent
+	SenderJID       string                       `json:"sender_jid" bun:"sender_jid,notnull"`     // JID of the poll author
+	Name            string                       `json:"name" bun:"name,notnull"`                 // The poll question
+	Options         []string                     `json:"options" bun:"options,array,notnull"`     // Names of the poll options, in order
+	SelectableCount int                          `json:"selectable_count" bun:"selectable_count"` // Maximum number of selectable options (0 means unlimited)
+	MessageSecret   []byte                       `json:"-" bun:"message_secret"`                  // Secret used to decrypt the votes
+	CreatedAt       time.Time                    `json:"created_at" bun:"created_at,notnull"`     // Timestamp when the poll was created
+}
+
+// PollVote represents the latest vote of a voter in a poll.
+type PollVote struct {
+	bun.BaseModel   `bun:"table:poll_vote,alias:pll_vt"` // Specifies the table name and alias
+	ID              int                                  `json:"id" bun:"id,pk,autoincrement"`                          // Primary key, auto-incremented
+	PollID          int                                  `json:"poll_id" bun:"poll_id,notnull"`                         // Foreign key to the poll table
+	VoterJID        string                               `json:"voter_jid" bun:"voter_jid,notnull"`                     // JID of the voter
+	SelectedOptions []string                             `json:"selected_options" bun:"selected_options,array,notnull"` // Names of the selected options
+	VotedAt         time.Time                            `json:"voted_at" bun:"voted_at,notnull"`                       // Timestamp of the vote
+}
+
+// PollContent holds the question and options of a poll message.
+type PollContent struct {
+	Name            string   `firestore:"name" json:"name"`                         // The poll question
+	Options         []string `firestore:"options" json:"options"`                   // Names of the poll options
+	SelectableCount int      `firestore:"selectable_count" json:"selectable_count"` // Maximum number of selectable options (0 means unlimited)
+}
+
+// PollVoteContent holds a decrypted poll vote.
+type PollVoteContent struct {
+	PollMessageID   string   `firestore:"poll_message_id" json:"poll_message_id"`   // WhatsApp ID of the poll being voted on
+	PollName        string   `firestore:"poll_name" json:"poll_name"`               // The poll question
+	SelectedOptions []string `firestore:"selected_options" json:"selected_options"` // Names of the selected options (empty when the vote was removed)
+}
+
+// PollOptionResult holds the tally of a single poll option.
+type PollOptionResult struct {
+	Name   string   `json:"name"`   // Name of the option
+	Votes  int      `json:"votes"`  // Number of voters that selected the option
+	Voters []string `json:"voters"` // JIDs of the voters that selected the option
+}
+
+// PollResults holds the running tally of a poll.
+type PollResults struct {
+	MessageID   string             `json:"message_id"`   // WhatsApp ID of the poll creation message
+	ChatJID     string             `json:"chat_jid"`     // JID of the chat where the poll was sent
+	Name        string             `json:"name"`         // The poll question
+	TotalVoters int                `json:"total_voters"` // Number of voters with at least one selected option
+	Options     []PollOptionResult `json:"options"`      // Tally of each option, in the poll order
+}
+
+// pollCreationFromMessage returns the poll creation content of a message, whichever version it uses.
+func pollCreationFromMessage(message *waE2E.Message) *waE2E.PollCreationMessage {
+	switch {
+	case message.GetPollCreationMessage() != nil:
+		return message.GetPollCreationMessage()
+	case message.GetPollCreationMessageV2() != nil:
+		return message.GetPollCreationMessageV2()
+	case message.GetPollCreationMessageV3() != nil:
+		return message.GetPollCreationMessageV3()
+	}
+	return nil
+}
+
+// ConvertPollCreation returns the stored representation of the poll carried by the message, or nil if it has none.
+func ConvertPollCreation(message *waE2E.Message) *PollContent {
+	pollCreation := pollCreationFromMessage(message)
+	if pollCreation == nil {
+		return nil
+	}
+	return pollContentFromMessage(pollCreation)
+}
+
+// pollContentFromMessage converts a poll creation message into its stored representation.
+func pollContentFromMessage(pollCreation *waE2E.PollCreationMessage) *PollContent {
+	options := make([]string, len(pollCreation.GetOptions()))
+	for i, option := range pollCreation.GetOptions() {
+		options[i] = option.GetOptionName()
+	}
+
+	return &PollContent{
+		Name:            pollCreation.GetName(),
+		Options:         options,
+		SelectableCount: int(pollCreation.GetSelectableOptionsCount()),
+	}
+}
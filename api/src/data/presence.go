@@ -0,0 +1,17 @@
+package data
+
+import (
+	"time"
+)
+
+// EventTypePresence is the event type of the webhooks sent when a tracked contact goes online or offline.
+const EventTypePresence = "PRESENCE"
+
+// ContactPresence represents the last known online state of a contact.
+type ContactPresence struct {
+	EventType string     `json:"event_type"`          // Always PRESENCE
+	JID       string     `json:"jid"`                 // JID of the contact
+	Online    bool       `json:"online"`              // Whether the contact is currently online
+	LastSeen  *time.Time `json:"last_seen,omitempty"` // When the contact was last online, unless hidden by their privacy settings
+	UpdatedAt time.Time  `json:"updated_at"`          // When the presence was received
+}
@@ -0,0 +1,22 @@
+package data
+
+import "time"
+
+// MessageSearch holds the text query and filters of a full-text search over the message history.
+type MessageSearch struct {
+	Query     string     // Words or phrases to search for (web search syntax: "quoted phrase", or, -excluded)
+	ChatJID   string     // Only messages of this chat, empty for all chats
+	SenderJID string     // Only messages of this author, empty for all authors
+	MediaType string     // Only messages of this media type (TEXT, IMAGE, ...), empty for all
+	Since     *time.Time // Only messages sent at or after this time
+	Until     *time.Time // Only messages sent before this time
+	Limit     int        // Maximum number of results returned
+	Offset    int        // Number of results to skip
+}
+
+// MessageSearchResult is a message matching a search, with the matched words highlighted.
+type MessageSearchResult struct {
+	Message *StoredMessage `json:"message"` // Matching message
+	Snippet string         `json:"snippet"` // Excerpt of the text with the matches wrapped in <b></b>
+	Rank    float64        `json:"rank"`    // Relevance of the message for the query
+}
@@ -6,33 +6,76 @@ import (
 	"time"
 
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
 
+// Event types carried by StoredMessage, so consumers can tell new messages apart from changes to existing ones.
+const (
+	EventTypeMessage       = "MESSAGE"        // A new message
+	EventTypeMessageEdit   = "MESSAGE_EDIT"   // An existing message had its text edited
+	EventTypeMessageRevoke = "MESSAGE_REVOKE" // An existing message was deleted for everyone
+	EventTypePollVote      = "POLL_VOTE"      // A vote was cast (or changed) on a poll
+)
+
 // StoredMessage represents a message stored in the database.
 type StoredMessage struct {
-	MessageID       string    `firestore:"message_id" json:"message_id"`               // Unique identifier for the message
-	IsFromMe        bool      `firestore:"is_from_me" json:"is_from_me"`               // Whether the message was sent by the current user
-	IsFromGroup     bool      `firestore:"is_from_group" json:"is_from_group"`         // Whether the message is from a group chat
-	MediaType       string    `firestore:"media_type" json:"media_type"`               // Type of message (TEXT, IMAGE, VIDEO, etc.)
-	Text            string    `firestore:"text" json:"text"`                           // Text content of the message (if applicable)
-	Content         []byte    `firestore:"content" json:"content"`                     // Raw content of the message (for media)
-	ContentMimeType string    `firestore:"content_mime_type" json:"content_mime_type"` // Mime type of the content media
-	RecipientID     string    `firestore:"recipient_id" json:"recipient_id"`           // WhatsApp ID of the recipient
-	RecipientName   string    `firestore:"push_name" json:"push_name"`                 // Display name of the recipient
-	Timestamp       time.Time `firestore:"timestamp" json:"timestamp"`                 // Timestamp of the message
+	EventType       string           `firestore:"event_type" json:"event_type"`                         // Kind of event (MESSAGE, MESSAGE_EDIT, MESSAGE_REVOKE, POLL_VOTE)
+	MessageID       string           `firestore:"message_id" json:"message_id"`                         // Unique identifier for the message
+	IsFromMe        bool             `firestore:"is_from_me" json:"is_from_me"`                         // Whether the message was sent by the current user
+	IsFromGroup     bool             `firestore:"is_from_group" json:"is_from_group"`                   // Whether the message is from a group chat
+	MediaType       string           `firestore:"media_type" json:"media_type"`                         // Type of message (TEXT, IMAGE, VIDEO, etc.)
+	Text            string           `firestore:"text" json:"text"`                                     // Text content of the message (if applicable)
+	Content         []byte           `firestore:"content" json:"content,omitempty"`                     // Raw content of the message (for media), replaced by Media once stored
+	Media           *MediaInfo       `firestore:"media" json:"media,omitempty"`                         // Stored media of the message, with its download URL
+	ContentMimeType string           `firestore:"content_mime_type" json:"content_mime_type"`           // Mime type of the content media
+	RecipientID     string           `firestore:"recipient_id" json:"recipient_id"`                     // WhatsApp ID of the recipient
+	RecipientName   string           `firestore:"push_name" json:"push_name"`                           // Display name of the recipient
+	ChatJID         string           `firestore:"chat_jid" json:"chat_jid"`                             // Full JID of the chat (user or group)
+	SenderJID       string           `firestore:"sender_jid" json:"sender_jid"`                         // Full JID of the message author
+	TargetMessageID string           `firestore:"target_message_id" json:"target_message_id,omitempty"` // ID of the message a reaction, edit or revoke refers to
+	Location        *Location        `firestore:"location" json:"location,omitempty"`                   // Coordinates of location messages
+	Contacts        []Contact        `firestore:"contacts" json:"contacts,omitempty"`                   // Parsed contact cards of contact messages
+	Poll            *PollContent     `firestore:"poll" json:"poll,omitempty"`                           // Question and options of poll messages
+	PollVote        *PollVoteContent `firestore:"poll_vote" json:"poll_vote,omitempty"`                 // Decrypted vote of poll vote events
+	MentionedJIDs   []string         `firestore:"mentioned_jids" json:"mentioned_jids,omitempty"`       // JIDs of the users mentioned in the text
+	MentionedMe     bool             `firestore:"mentioned_me" json:"mentioned_me"`                     // Whether the device itself was mentioned
+	Timestamp       time.Time        `firestore:"timestamp" json:"timestamp"`                           // Timestamp of the message
 }
 
 // ConvertEventToStoredMessage converts a WhatsApp event message into a StoredMessage structure.
 // It extracts media or text content and assigns the appropriate fields in the StoredMessage.
+// The client may be nil for messages sent by the API itself, whose media is already known and is not downloaded.
 func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*StoredMessage, error) {
 	messageContent := StoredMessage{
-		MessageID:     v.Info.ID,        // Unique identifier for the message
-		RecipientID:   v.Info.Chat.User, // WhatsApp ID of the recipient
-		Timestamp:     v.Info.Timestamp, // Timestamp when the message was sent/received
-		IsFromMe:      v.Info.IsFromMe,  // Indicates if the message was sent by the user
-		IsFromGroup:   v.Info.IsGroup,   // Indicates if the message belongs to a group chat
-		RecipientName: v.Info.PushName,  // Display name of the recipient
+		EventType:     EventTypeMessage,                 // New message unless it turns out to be an edit or revoke
+		MessageID:     v.Info.ID,                        // Unique identifier for the message
+		RecipientID:   v.Info.Chat.User,                 // WhatsApp ID of the recipient
+		Timestamp:     v.Info.Timestamp,                 // Timestamp when the message was sent/received
+		IsFromMe:      v.Info.IsFromMe,                  // Indicates if the message was sent by the user
+		IsFromGroup:   v.Info.IsGroup,                   // Indicates if the message belongs to a group chat
+		RecipientName: v.Info.PushName,                  // Display name of the recipient
+		ChatJID:       v.Info.Chat.String(),             // Full JID of the chat
+		SenderJID:     v.Info.Sender.ToNonAD().String(), // Full JID of the message author
+	}
+
+	// Handle edits and revokes of existing messages
+	if protocolMessage := v.Message.GetProtocolMessage(); protocolMessage != nil {
+		switch protocolMessage.GetType() {
+		case waE2E.ProtocolMessage_MESSAGE_EDIT:
+			messageContent.EventType = EventTypeMessageEdit
+			messageContent.MediaType = "TEXT"
+			messageContent.Text = extractMessageText(protocolMessage.GetEditedMessage())
+			messageContent.TargetMessageID = protocolMessage.GetKey().GetID()
+			messageContent.ContentMimeType = "text/plain"
+			return &messageContent, nil
+		case waE2E.ProtocolMessage_REVOKE:
+			messageContent.EventType = EventTypeMessageRevoke
+			messageContent.MediaType = "REVOKE"
+			messageContent.TargetMessageID = protocolMessage.GetKey().GetID()
+			return &messageContent, nil
+		}
 	}
 
 	// Handle Image Messages
@@ -42,7 +85,7 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 			messageContent.Text = v.Message.GetImageMessage().GetCaption()
 		}
 		messageContent.ContentMimeType = v.Message.ImageMessage.GetMimetype()
-		content, err := client.Download(v.Message.ImageMessage)
+		content, err := downloadMedia(client, v.Message.ImageMessage)
 		messageContent.Content = content
 		return &messageContent, err
 	}
@@ -50,8 +93,9 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 	// Handle Video Messages
 	if v.Message.VideoMessage != nil {
 		messageContent.MediaType = "VIDEO"
+		messageContent.Text = v.Message.GetVideoMessage().GetCaption()
 		messageContent.ContentMimeType = v.Message.VideoMessage.GetMimetype()
-		content, err := client.Download(v.Message.VideoMessage)
+		content, err := downloadMedia(client, v.Message.VideoMessage)
 		messageContent.Content = content
 		return &messageContent, err
 	}
@@ -60,7 +104,7 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 	if v.Message.AudioMessage != nil {
 		messageContent.MediaType = "AUDIO"
 		messageContent.ContentMimeType = v.Message.AudioMessage.GetMimetype()
-		content, err := client.Download(v.Message.AudioMessage)
+		content, err := downloadMedia(client, v.Message.AudioMessage)
 		messageContent.Content = content
 		messageContent.Text = "" // Placeholder for potential speech-to-text functionality
 		return &messageContent, err
@@ -70,7 +114,7 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 	if v.Message.StickerMessage != nil {
 		messageContent.MediaType = "STICKER"
 		messageContent.ContentMimeType = v.Message.StickerMessage.GetMimetype()
-		content, err := client.Download(v.Message.StickerMessage)
+		content, err := downloadMedia(client, v.Message.StickerMessage)
 		messageContent.Content = content
 		return &messageContent, err
 	}
@@ -78,12 +122,55 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 	// Handle Document Messages
 	if v.Message.DocumentMessage != nil {
 		messageContent.MediaType = "DOCUMENT"
+		messageContent.Text = v.Message.GetDocumentMessage().GetCaption()
 		messageContent.ContentMimeType = v.Message.DocumentMessage.GetMimetype()
-		content, err := client.Download(v.Message.DocumentMessage)
+		content, err := downloadMedia(client, v.Message.DocumentMessage)
 		messageContent.Content = content
 		return &messageContent, err
 	}
 
+	// Handle Location and Live Location Messages
+	if v.Message.LocationMessage != nil {
+		messageContent.MediaType = "LOCATION"
+		messageContent.Location = locationFromMessage(v.Message.GetLocationMessage())
+		messageContent.Text = v.Message.GetLocationMessage().GetComment()
+		messageContent.ContentMimeType = "text/plain"
+		return &messageContent, nil
+	}
+
+	if v.Message.LiveLocationMessage != nil {
+		messageContent.MediaType = "LOCATION"
+		messageContent.Location = locationFromLiveMessage(v.Message.GetLiveLocationMessage())
+		messageContent.Text = v.Message.GetLiveLocationMessage().GetCaption()
+		messageContent.ContentMimeType = "text/plain"
+		return &messageContent, nil
+	}
+
+	// Handle Contact and Multi-Contact Messages
+	if v.Message.ContactMessage != nil || v.Message.ContactsArrayMessage != nil {
+		messageContent.MediaType = "CONTACT"
+		messageContent.Contacts = contactsFromMessage(v.Message)
+		messageContent.ContentMimeType = "text/vcard"
+		return &messageContent, nil
+	}
+
+	// Handle Poll Messages
+	if pollCreation := pollCreationFromMessage(v.Message); pollCreation != nil {
+		messageContent.MediaType = "POLL"
+		messageContent.Poll = pollContentFromMessage(pollCreation)
+		messageContent.Text = pollCreation.GetName()
+		messageContent.ContentMimeType = "text/plain"
+		return &messageContent, nil
+	}
+
+	// Handle Poll Votes (votes are encrypted and decrypted later against the stored poll)
+	if v.Message.PollUpdateMessage != nil {
+		messageContent.EventType = EventTypePollVote
+		messageContent.MediaType = "POLL_VOTE"
+		messageContent.TargetMessageID = v.Message.GetPollUpdateMessage().GetPollCreationMessageKey().GetID()
+		return &messageContent, nil
+	}
+
 	// Handle Text and Extended Text Messages
 	if v.Message.Conversation != nil {
 		messageContent.MediaType = "TEXT"
@@ -96,6 +183,17 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 		messageContent.MediaType = "TEXT"
 		messageContent.Text = v.Message.GetExtendedTextMessage().GetText()
 		messageContent.ContentMimeType = "text/plain"
+		messageContent.MentionedJIDs = v.Message.GetExtendedTextMessage().GetContextInfo().GetMentionedJID()
+		messageContent.MentionedMe = isDeviceMentioned(messageContent.MentionedJIDs, client)
+		return &messageContent, nil
+	}
+
+	// Handle Reaction Messages (the emoji is empty when a reaction is removed)
+	if v.Message.ReactionMessage != nil {
+		messageContent.MediaType = "REACTION"
+		messageContent.Text = v.Message.GetReactionMessage().GetText()
+		messageContent.TargetMessageID = v.Message.GetReactionMessage().GetKey().GetID()
+		messageContent.ContentMimeType = "text/plain"
 		return &messageContent, nil
 	}
 
@@ -111,3 +209,41 @@ func ConvertEventToStoredMessage(v events.Message, client *whatsmeow.Client) (*S
 
 	return &messageContent, nil
 }
+
+// downloadMedia downloads the media of a message, skipping the download when there is no client.
+func downloadMedia(client *whatsmeow.Client, media whatsmeow.DownloadableMessage) ([]byte, error) {
+	if client == nil {
+		return nil, nil
+	}
+	return client.Download(media)
+}
+
+// isDeviceMentioned reports whether the device of the client is among the mentioned JIDs.
+func isDeviceMentioned(mentionedJIDs []string, client *whatsmeow.Client) bool {
+	if client == nil || client.Store.ID == nil {
+		return false
+	}
+	for _, mentioned := range mentionedJIDs {
+		if jid, err := types.ParseJID(mentioned); err == nil && jid.User == client.Store.ID.User {
+			return true
+		}
+	}
+	return false
+}
+
+// extractMessageText returns the text of a message: the conversation text, extended text or media caption.
+func extractMessageText(message *waE2E.Message) string {
+	switch {
+	case message.GetConversation() != "":
+		return message.GetConversation()
+	case message.GetExtendedTextMessage() != nil:
+		return message.GetExtendedTextMessage().GetText()
+	case message.GetImageMessage() != nil:
+		return message.GetImageMessage().GetCaption()
+	case message.GetVideoMessage() != nil:
+		return message.GetVideoMessage().GetCaption()
+	case message.GetDocumentMessage() != nil:
+		return message.GetDocumentMessage().GetCaption()
+	}
+	return ""
+}
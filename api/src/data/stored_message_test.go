@@ -0,0 +1,33 @@
+package data
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConvertEventKeepsMediaCaptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   *waE2E.Message
+		mediaType string
+	}{
+		{"image", &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("caption")}}, "IMAGE"},
+		{"video", &waE2E.Message{VideoMessage: &waE2E.VideoMessage{Caption: proto.String("caption")}}, "VIDEO"},
+		{"document", &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{Caption: proto.String("caption")}}, "DOCUMENT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := ConvertEventToStoredMessage(events.Message{Message: tt.message}, nil)
+			if err != nil {
+				t.Fatalf("ConvertEventToStoredMessage: %v", err)
+			}
+			if stored.MediaType != tt.mediaType || stored.Text != "caption" {
+				t.Errorf("got %s with text %q, want %s with text %q", stored.MediaType, stored.Text, tt.mediaType, "caption")
+			}
+		})
+	}
+}
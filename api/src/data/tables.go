@@ -8,5 +8,10 @@ func TablesPostgres() []interface{} {
 		(*DeviceHandler)(nil),  // DeviceHandler model for managing device handler states.
 		(*DeviceWebhook)(nil),  // DeviceWebhook model for storing webhook configurations.
 		(*WebhookMessage)(nil), // WebhookMessage model for managing messages sent via webhooks.
+		(*Poll)(nil),           // Poll model for the polls sent or received by the devices.
+		(*PollVote)(nil),       // PollVote model for the latest vote of each voter in a poll.
+		(*MessageStatus)(nil),  // MessageStatus model for the delivery status of sent messages.
+		(*Message)(nil),        // Message model for the history of received and sent messages.
+		(*Media)(nil),          // Media model for the media contents kept in the media store.
 	}
 }
@@ -63,34 +63,55 @@ func AddToListeners(whatsappID string) {
 }
 
 // StartMessageListener starts listening for messages from a specific WhatsApp device.
-// It registers a message handler and handles different event types (messages and logout events).
+// It registers a message handler on the shared device client and handles different event types (messages, group changes, receipts, presences and logout events).
+// Calling it again for the same device replaces the previous handler instead of adding a duplicate one.
 func StartMessageListener(whatsappID string) (*whatsmeow.Client, error) {
-	// Get the WhatsApp client for the device.
+	// Retrieve the device details and webhook information.
+	device, err := store.GetDeviceByJID(whatsappID)
+	if err != nil {
+		return nil, err
+	}
+	webhookURL, webhookActive, _ := store.GetWebhookURLByDeviceID(device.ID)
+
+	// Get the shared WhatsApp client for the device.
 	client, err := helpers.GetWhatsAppClientByJID(whatsappID)
 	if err != nil {
 		handler.FailOnError(err, fmt.Sprintf("Error getting client for WhatsApp ID: %s", whatsappID))
 		return nil, err
 	}
 
-	// Retrieve the device details and webhook information.
-	device, _ := store.GetDeviceByJID(whatsappID)
-	webhookURL, webhookActive, _ := store.GetWebhookURLByDeviceID(device.ID)
-
-	// Add an event handler for incoming messages and logout events.
-	client.AddEventHandler(func(evt interface{}) {
+	// Register the event handler for incoming messages, group changes, receipts, presences and logout events, replacing any previous one.
+	_, err = helpers.GetClientManager().SetListener(whatsappID, func(evt interface{}) {
 		switch event := evt.(type) {
 		case *events.Message:
 			handleMessageEvent(event, client, device.ID, webhookURL, webhookActive)
+		case *events.GroupInfo:
+			handleGroupEvents(data.ConvertGroupInfoEvent(event), device.ID, webhookURL, webhookActive)
+		case *events.JoinedGroup:
+			handleGroupEvents([]data.GroupEvent{data.ConvertJoinedGroupEvent(event)}, device.ID, webhookURL, webhookActive)
+		case *events.Receipt:
+			handleReceiptEvent(event, device.ID, webhookURL, webhookActive)
+		case *events.Presence:
+			if presence := helpers.RecordPresence(device.ID, event); presence != nil {
+				go helpers.SendWebhook(*presence, device.ID, webhookURL, webhookActive)
+			}
+		case *events.Connected:
+			// Presence subscriptions do not survive reconnections.
+			go helpers.ResubscribePresences(client, device.ID)
 		case *events.LoggedOut:
-			helpers.LogoutDeviceByJID(client.Store.ID.String())
+			helpers.LogoutDeviceByJID(whatsappID)
 		}
 	})
+	if err != nil {
+		handler.FailOnError(err, fmt.Sprintf("Error registering listener for WhatsApp ID: %s", whatsappID))
+		return nil, err
+	}
 
 	log.Printf("Started message listener for %s", device.JID)
 	return client, nil
 }
 
-// handleMessageEvent processes incoming messages by storing them and sending them to Redis and Webhook.
+// handleMessageEvent processes incoming messages by storing them in the message history and sending them to Redis and Webhook.
 // It runs tasks concurrently for performance.
 func handleMessageEvent(msgEvent *events.Message, client *whatsmeow.Client, deviceID int, webhookURL string, webhookActive bool) {
 	ctx := context.Background()
@@ -102,17 +123,59 @@ func handleMessageEvent(msgEvent *events.Message, client *whatsmeow.Client, devi
 		return
 	}
 
+	// Keep polls to tally their votes, and decrypt incoming votes against them.
+	switch content.MediaType {
+	case "POLL":
+		helpers.SavePollFromEvent(deviceID, msgEvent)
+	case "POLL_VOTE":
+		// A vote that cannot be decrypted is still delivered and stored, without its selected options.
+		if err := helpers.ResolvePollVote(client, msgEvent, deviceID, content); err != nil {
+			handler.FailOnError(err, "Error resolving poll vote")
+		}
+	}
+
+	// Write the downloaded media to the media store, so it is not carried inline by webhooks and Redis.
+	if err := helpers.StoreMessageMedia(content); err != nil {
+		handler.FailOnError(err, "Error storing message media")
+	}
+
+	// Store the message in the message history.
+	if err := store.SaveMessage(deviceID, content); err != nil {
+		handler.FailOnError(err, "Error saving message to the message history")
+	}
+
 	// Send the message to Redis and Webhook concurrently.
 	go helpers.SendMessageToRedis(ctx, *content, deviceID)
 	go helpers.SendWebhook(*content, deviceID, webhookURL, webhookActive)
 }
 
+// handleGroupEvents sends the normalised group events to Redis and Webhook concurrently, like messages.
+func handleGroupEvents(groupEvents []data.GroupEvent, deviceID int, webhookURL string, webhookActive bool) {
+	ctx := context.Background()
+
+	for _, groupEvent := range groupEvents {
+		go helpers.SendMessageToRedis(ctx, groupEvent, deviceID)
+		go helpers.SendWebhook(groupEvent, deviceID, webhookURL, webhookActive)
+	}
+}
+
+// handleReceiptEvent records the delivery, read and played receipts of sent messages and sends a webhook
+// for every status that advanced.
+func handleReceiptEvent(receipt *events.Receipt, deviceID int, webhookURL string, webhookActive bool) {
+	for _, statusEvent := range helpers.RecordReceipt(deviceID, receipt) {
+		go helpers.SendWebhook(statusEvent, deviceID, webhookURL, webhookActive)
+	}
+}
+
 // NewClientHandler returns a handler function that is triggered when the client connects.
 // It inserts the device into the database if it doesn't exist and starts the message listener.
 func NewClientHandler(client *whatsmeow.Client) func(interface{}) {
 	return func(evt interface{}) {
 		// Check if the event is a successful connection event.
 		if _, ok := evt.(*events.Connected); ok {
+			// Hand the paired client over to the client manager so the listener and routes reuse it.
+			helpers.GetClientManager().Register(client)
+
 			// Get the device information from the client store.
 			storeDevice := client.Store
 			device := &data.Device{
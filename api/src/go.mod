@@ -6,6 +6,7 @@ toolchain go1.23.1
 
 require (
 	github.com/chai2010/webp v1.1.1
+	github.com/gabriel-vasile/mimetype v1.4.4
 	github.com/gin-gonic/gin v1.10.0
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/google/uuid v1.6.0
@@ -19,6 +20,8 @@ require (
 	github.com/uptrace/bun/driver/pgdriver v1.2.3
 	github.com/ztrue/tracerr v0.4.0
 	go.mau.fi/whatsmeow v0.0.0-20241011190419-de8326a9d38d
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.29.0
 	google.golang.org/protobuf v1.35.1
 )
 
@@ -30,7 +33,6 @@ require (
 	github.com/cloudwego/base64x v0.1.4 // indirect
 	github.com/cloudwego/iasm v0.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/gabriel-vasile/mimetype v1.4.4 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
@@ -67,7 +69,6 @@ require (
 	go.mau.fi/util v0.8.0 // indirect
 	golang.org/x/arch v0.8.0 // indirect
 	golang.org/x/crypto v0.27.0 // indirect
-	golang.org/x/net v0.29.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+const (
+	// OpusMimeType is the mime type WhatsApp expects for voice notes.
+	OpusMimeType = "audio/ogg; codecs=opus"
+	// waveformSamples is the number of bars in the waveform shown by WhatsApp for voice notes.
+	waveformSamples = 64
+	// waveformSampleRate is the sample rate of the PCM used to compute duration and waveform.
+	waveformSampleRate = 8000
+)
+
+// nativeAudioMimeTypes lists audio formats WhatsApp plays as regular audio files without conversion.
+var nativeAudioMimeTypes = map[string]bool{
+	"audio/mpeg": true,
+	"audio/mp4":  true,
+	"audio/aac":  true,
+	"audio/ogg":  true,
+}
+
+// ConvertedAudio holds an audio file ready to be uploaded to WhatsApp.
+type ConvertedAudio struct {
+	Data     []byte // Encoded audio content
+	MimeType string // Mime type of the encoded content
+	Seconds  uint32 // Duration of the audio in seconds
+	Waveform []byte // 64 amplitude values (0-100) used by WhatsApp to draw voice notes
+}
+
+// ConvertAudioToOpus transcodes an audio file (mp3, wav, m4a, ...) to OGG/Opus as used by WhatsApp voice notes.
+func ConvertAudioToOpus(ctx context.Context, fileBytes []byte) ([]byte, error) {
+	return runFFmpeg(ctx, fileBytes,
+		"-vn",      // Drop any cover art or video stream
+		"-ac", "1", // Voice notes are mono
+		"-ar", "48000", // Opus native sample rate
+		"-c:a", "libopus", // Encode with Opus
+		"-b:a", "32k",
+		"-application", "voip",
+		"-f", "ogg", "pipe:1",
+	)
+}
+
+// PrepareAudio converts the audio for sending and computes its duration and waveform.
+// Voice notes (ptt) are always transcoded to OGG/Opus; regular audio files keep their original
+// format when WhatsApp can play it natively. The conversion stops when the context is cancelled.
+func PrepareAudio(ctx context.Context, audio *MediaFile, ptt bool) (*ConvertedAudio, error) {
+	// Decode to raw PCM once to measure the duration and draw the waveform.
+	pcm, err := runFFmpeg(ctx, audio.Data, "-vn", "-ac", "1", "-ar", strconv.Itoa(waveformSampleRate), "-f", "s16le", "pipe:1")
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &ConvertedAudio{
+		Data:     audio.Data,
+		MimeType: audio.MimeType,
+		Seconds:  uint32(math.Ceil(float64(len(pcm)/2) / waveformSampleRate)),
+		Waveform: computeWaveform(pcm),
+	}
+
+	if ptt || !nativeAudioMimeTypes[audio.MimeType] {
+		converted.Data, err = ConvertAudioToOpus(ctx, audio.Data)
+		if err != nil {
+			return nil, err
+		}
+		converted.MimeType = OpusMimeType
+	}
+
+	return converted, nil
+}
+
+// computeWaveform reduces signed 16-bit little-endian mono PCM to 64 peak values scaled to 0-100.
+func computeWaveform(pcm []byte) []byte {
+	waveform := make([]byte, waveformSamples)
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return waveform
+	}
+
+	// Compute the average absolute amplitude of each bucket.
+	levels := make([]float64, waveformSamples)
+	var peak float64
+	for i := range levels {
+		start := i * samples / waveformSamples
+		end := max((i+1)*samples/waveformSamples, start+1)
+		var sum float64
+		for j := start; j < end && j < samples; j++ {
+			sum += math.Abs(float64(int16(binary.LittleEndian.Uint16(pcm[j*2:]))))
+		}
+		levels[i] = sum / float64(end-start)
+		peak = math.Max(peak, levels[i])
+	}
+
+	// Normalise against the loudest bucket so quiet recordings still show a visible waveform.
+	if peak == 0 {
+		return waveform
+	}
+	for i, level := range levels {
+		waveform[i] = byte(math.Round(level / peak * 100))
+	}
+	return waveform
+}
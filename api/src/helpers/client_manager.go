@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ztrue/tracerr"
+	"go.mau.fi/whatsmeow"
+)
+
+const (
+	// clientLoginTimeout is how long the manager waits for a freshly connected client to finish logging in.
+	clientLoginTimeout = 15 * time.Second
+)
+
+// managedClient holds the single WhatsApp client of a device together with its listener handler.
+type managedClient struct {
+	mu                sync.Mutex        // Guards the fields below; never held while connecting
+	client            *whatsmeow.Client // Long-lived WhatsApp client for the device
+	listenerHandlerID uint32            // ID of the event handler registered by the message listener
+	hasListener       bool              // Whether a listener handler is currently registered
+	connecting        *connectAttempt   // Connection attempt in progress, shared by concurrent callers
+}
+
+// connectAttempt is a connection attempt of a device client whose result is shared by every caller
+// that needed the client while it was connecting.
+type connectAttempt struct {
+	done chan struct{} // Closed when the attempt finished
+	err  error         // Result of the attempt, set before done is closed
+}
+
+// ClientManager owns one connected WhatsApp client per device JID.
+// The same client is shared by the message listener and every route that talks to WhatsApp,
+// so there is never more than one session open for a device.
+type ClientManager struct {
+	mu      sync.Mutex
+	clients map[string]*managedClient
+}
+
+var (
+	clientManagerInstance *ClientManager
+	clientManagerOnce     sync.Once
+)
+
+// GetClientManager returns the singleton ClientManager used by the application.
+func GetClientManager() *ClientManager {
+	clientManagerOnce.Do(func() {
+		clientManagerInstance = &ClientManager{
+			clients: make(map[string]*managedClient),
+		}
+	})
+	return clientManagerInstance
+}
+
+// entry returns the managed entry for a device, creating an empty one if needed.
+func (m *ClientManager) entry(whatsappID string) *managedClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mc, ok := m.clients[whatsappID]
+	if !ok {
+		mc = &managedClient{}
+		m.clients[whatsappID] = mc
+	}
+	return mc
+}
+
+// Get returns the connected WhatsApp client for the given device JID.
+// The client is created on first use and reconnected if its connection was lost. Concurrent callers
+// wait for the same connection attempt, and the entry is not locked while it runs, so other
+// operations on the device are not held up by a slow or failing connection.
+func (m *ClientManager) Get(whatsappID string) (*whatsmeow.Client, error) {
+	mc := m.entry(whatsappID)
+
+	mc.mu.Lock()
+	if mc.client == nil {
+		deviceStore, err := GetDeviceStoreByJID(whatsappID)
+		if err != nil {
+			mc.mu.Unlock()
+			return nil, err
+		}
+		if deviceStore.ID == nil {
+			mc.mu.Unlock()
+			return nil, tracerr.Wrap(fmt.Errorf("%w: %s", ErrDeviceNotFound, whatsappID))
+		}
+		client := whatsmeow.NewClient(deviceStore, wmLog)
+		client.EnableAutoReconnect = true
+		mc.client = client
+	}
+	client := mc.client
+
+	if client.IsConnected() && client.IsLoggedIn() {
+		mc.mu.Unlock()
+		return client, nil
+	}
+
+	// Join the attempt in progress, or start one.
+	attempt := mc.connecting
+	if attempt != nil {
+		mc.mu.Unlock()
+		<-attempt.done
+	} else {
+		attempt = &connectAttempt{done: make(chan struct{})}
+		mc.connecting = attempt
+		mc.mu.Unlock()
+
+		attempt.err = connectClient(client)
+
+		mc.mu.Lock()
+		mc.connecting = nil
+		mc.mu.Unlock()
+		close(attempt.done)
+	}
+
+	if attempt.err != nil {
+		return nil, attempt.err
+	}
+	return client, nil
+}
+
+// Register adds an already connected client (e.g. one that just finished pairing) to the manager.
+// If the device already had a different client, that one is disconnected and replaced.
+func (m *ClientManager) Register(client *whatsmeow.Client) {
+	if client.Store.ID == nil {
+		return
+	}
+	mc := m.entry(client.Store.ID.String())
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.client != nil && mc.client != client {
+		mc.client.Disconnect()
+		mc.hasListener = false
+	}
+	client.EnableAutoReconnect = true
+	mc.client = client
+}
+
+// SetListener registers the message listener handler on the device client,
+// replacing any listener that was previously registered for the same device.
+func (m *ClientManager) SetListener(whatsappID string, handler whatsmeow.EventHandler) (*whatsmeow.Client, error) {
+	client, err := m.Get(whatsappID)
+	if err != nil {
+		return nil, err
+	}
+
+	mc := m.entry(whatsappID)
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.hasListener {
+		client.RemoveEventHandler(mc.listenerHandlerID)
+	}
+	mc.listenerHandlerID = client.AddEventHandler(handler)
+	mc.hasListener = true
+
+	return client, nil
+}
+
+// Remove disconnects the client of a device and forgets it, e.g. after the device logged out.
+func (m *ClientManager) Remove(whatsappID string) {
+	m.mu.Lock()
+	mc, ok := m.clients[whatsappID]
+	delete(m.clients, whatsappID)
+	m.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.client != nil {
+		mc.client.Disconnect()
+	}
+}
+
+// DisconnectAll disconnects every managed client. It is used during application shutdown.
+func (m *ClientManager) DisconnectAll() {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*managedClient)
+	m.mu.Unlock()
+
+	for whatsappID, mc := range clients {
+		mc.mu.Lock()
+		if mc.client != nil {
+			mc.client.Disconnect()
+			log.Printf("Disconnected WhatsApp client for %s", whatsappID)
+		}
+		mc.mu.Unlock()
+	}
+}
+
+// connectClient connects the client if needed and waits until it is logged in.
+func connectClient(client *whatsmeow.Client) error {
+	if !client.IsConnected() {
+		if err := client.Connect(); err != nil && !errors.Is(err, whatsmeow.ErrAlreadyConnected) {
+			return tracerr.Wrap(fmt.Errorf("%w: %v", ErrClientConnection, err))
+		}
+	}
+
+	if !client.WaitForConnection(clientLoginTimeout) {
+		return tracerr.Wrap(fmt.Errorf("%w: timed out waiting for login", ErrClientConnection))
+	}
+	return nil
+}
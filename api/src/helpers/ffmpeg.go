@@ -0,0 +1,129 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MediaCommandTimeout is the longest an ffmpeg or ffprobe run may take before it is killed.
+	MediaCommandTimeout = 2 * time.Minute
+)
+
+// Error definitions for media conversion issues.
+var (
+	ErrMediaConversion     = errors.New("failed to convert media")
+	ErrMediaToolsNotFound  = errors.New("ffmpeg or ffprobe not found")
+	ErrMediaCommandTimeout = errors.New("media conversion timed out")
+)
+
+// ffmpegBinary returns the ffmpeg executable to use, configurable through FFMPEG_PATH.
+func ffmpegBinary() string {
+	return getEnv("FFMPEG_PATH", "ffmpeg")
+}
+
+// ffprobeBinary returns the ffprobe executable to use, configurable through FFPROBE_PATH.
+func ffprobeBinary() string {
+	return getEnv("FFPROBE_PATH", "ffprobe")
+}
+
+var (
+	mediaToolsErr   error        // Result of CheckMediaTools, nil while the tools are available
+	mediaToolsMutex sync.RWMutex // Guards mediaToolsErr
+)
+
+// CheckMediaTools verifies at startup that the ffmpeg and ffprobe executables used to convert
+// audio and video can be found, and remembers the result for RequireMediaTools.
+func CheckMediaTools() error {
+	var err error
+	for _, binary := range []string{ffmpegBinary(), ffprobeBinary()} {
+		if _, lookErr := exec.LookPath(binary); lookErr != nil {
+			err = fmt.Errorf("%w: %v", ErrMediaToolsNotFound, lookErr)
+			break
+		}
+	}
+
+	mediaToolsMutex.Lock()
+	defer mediaToolsMutex.Unlock()
+	mediaToolsErr = err
+	return err
+}
+
+// RequireMediaTools returns ErrMediaToolsNotFound when CheckMediaTools found ffmpeg or ffprobe missing,
+// so only the audio and video sends are refused while everything else keeps working.
+func RequireMediaTools() error {
+	mediaToolsMutex.RLock()
+	defer mediaToolsMutex.RUnlock()
+	return mediaToolsErr
+}
+
+// runFFmpeg runs ffmpeg on the input and returns whatever it wrote to stdout.
+func runFFmpeg(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+	return withTempInput(input, func(inputPath string) ([]byte, error) {
+		cmdArgs := append([]string{"-hide_banner", "-loglevel", "error", "-i", inputPath}, args...)
+		return runMediaCommand(ctx, ffmpegBinary(), cmdArgs...)
+	})
+}
+
+// runFFprobe runs ffprobe on the input and returns whatever it wrote to stdout.
+func runFFprobe(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+	return withTempInput(input, func(inputPath string) ([]byte, error) {
+		cmdArgs := append([]string{"-v", "error"}, args...)
+		return runMediaCommand(ctx, ffprobeBinary(), append(cmdArgs, inputPath)...)
+	})
+}
+
+// withTempInput writes the input to a temporary file and calls fn with its path.
+// A file is used instead of stdin because containers such as MP4/M4A cannot be reliably read from a pipe.
+func withTempInput(input []byte, fn func(inputPath string) ([]byte, error)) ([]byte, error) {
+	inputFile, err := os.CreateTemp("", "uatzapi-media-*")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaConversion, err)
+	}
+	defer func(name string) {
+		if err := os.Remove(name); err != nil {
+			fmt.Printf("Error removing temporary media file: %v\n", err)
+		}
+	}(inputFile.Name())
+
+	if _, err := inputFile.Write(input); err != nil {
+		_ = inputFile.Close()
+		return nil, fmt.Errorf("%w: %v", ErrMediaConversion, err)
+	}
+	if err := inputFile.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaConversion, err)
+	}
+
+	return fn(inputFile.Name())
+}
+
+// runMediaCommand runs the given media tool and returns its stdout, including stderr in the error on failure.
+// The tool is killed when the context is cancelled (e.g. the client disconnects) or after MediaCommandTimeout.
+func runMediaCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, MediaCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %s after %s", ErrMediaCommandTimeout, name, MediaCommandTimeout)
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMediaConversion, ctx.Err())
+		}
+		return nil, fmt.Errorf("%w: %v: %s", ErrMediaConversion, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
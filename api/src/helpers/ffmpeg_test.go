@@ -0,0 +1,22 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRequireMediaToolsAfterFailedCheck(t *testing.T) {
+	t.Setenv("FFMPEG_PATH", "/nonexistent/ffmpeg")
+	t.Cleanup(func() {
+		mediaToolsMutex.Lock()
+		mediaToolsErr = nil
+		mediaToolsMutex.Unlock()
+	})
+
+	if err := CheckMediaTools(); !errors.Is(err, ErrMediaToolsNotFound) {
+		t.Fatalf("CheckMediaTools = %v, want ErrMediaToolsNotFound", err)
+	}
+	if err := RequireMediaTools(); !errors.Is(err, ErrMediaToolsNotFound) {
+		t.Fatalf("RequireMediaTools = %v, want ErrMediaToolsNotFound", err)
+	}
+}
@@ -3,10 +3,18 @@ package helpers
 import (
 	"bytes"
 	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
 	"image"
-	_ "image/gif"  // Import support for GIF
-	_ "image/jpeg" // Import support for JPEG
-	_ "image/png"  // Import support for PNG
+	_ "image/gif" // Import support for GIF
+	"image/jpeg"
+	_ "image/png" // Import support for PNG
+)
+
+const (
+	// ThumbnailMaxSize is the maximum width or height, in pixels, of generated JPEG thumbnails.
+	ThumbnailMaxSize = 72
+	// ProfilePictureSize is the maximum width and height, in pixels, of profile and group pictures.
+	ProfilePictureSize = 640
 )
 
 // decodeImage attempts to decode an image from the provided byte slice.
@@ -46,3 +54,76 @@ func ConvertImageToWebp(fileBytes []byte) ([]byte, error) {
 	// Return the WebP image bytes.
 	return buf.Bytes(), nil
 }
+
+// GenerateJPEGThumbnail decodes an image and returns a small JPEG thumbnail of it,
+// together with the width and height of the original image.
+func GenerateJPEGThumbnail(fileBytes []byte) ([]byte, int, int, error) {
+	img, err := decodeImage(fileBytes)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	thumbnail, err := EncodeJPEGThumbnail(img)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	bounds := img.Bounds()
+	return thumbnail, bounds.Dx(), bounds.Dy(), nil
+}
+
+// EncodeJPEGThumbnail scales the image down to fit in ThumbnailMaxSize pixels, keeping its aspect ratio,
+// and encodes it as JPEG.
+func EncodeJPEGThumbnail(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Compute the thumbnail dimensions keeping the aspect ratio.
+	if width > ThumbnailMaxSize || height > ThumbnailMaxSize {
+		if width >= height {
+			height = max(1, height*ThumbnailMaxSize/width)
+			width = ThumbnailMaxSize
+		} else {
+			width = max(1, width*ThumbnailMaxSize/height)
+			height = ThumbnailMaxSize
+		}
+	}
+
+	// Scale the image into the thumbnail canvas.
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(thumbnail, thumbnail.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 75}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ConvertImageToProfilePicture center-crops the image to a square, scales it down to ProfilePictureSize
+// pixels when larger and encodes it as JPEG, which is the format WhatsApp accepts for profile and group pictures.
+func ConvertImageToProfilePicture(fileBytes []byte) ([]byte, error) {
+	img, err := decodeImage(fileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Crop the largest centered square of the image.
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	// Scale the square into the picture canvas.
+	size := min(side, ProfilePictureSize)
+	picture := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.ApproxBiLinear.Scale(picture, picture.Bounds(), img, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, picture, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -2,11 +2,10 @@ package helpers
 
 import (
 	"encoding/json"
-	"whatsgoingon/data"
 )
 
-// MarshalMessageToJSON converts a StoredMessage struct into a JSON byte array.
+// MarshalMessageToJSON converts a message (a StoredMessage or any other event) into a JSON byte array.
 // Returns the JSON-encoded bytes or an error if marshalling fails.
-func MarshalMessageToJSON(content data.StoredMessage) ([]byte, error) {
+func MarshalMessageToJSON(content interface{}) ([]byte, error) {
 	return json.Marshal(content)
 }
@@ -0,0 +1,191 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// DefaultLinkPreviewTimeout is the time allowed to fetch the page and its preview image.
+	DefaultLinkPreviewTimeout = 5 * time.Second
+	// DefaultLinkPreviewMaxBytes is the largest part of the page read while looking for OpenGraph tags (512 KB).
+	DefaultLinkPreviewMaxBytes = 512 << 10
+	// DefaultLinkPreviewMaxImageBytes is the largest preview image downloaded to build the thumbnail (2 MB).
+	DefaultLinkPreviewMaxImageBytes = 2 << 20
+)
+
+// ErrLinkPreview is returned when the preview of a link cannot be fetched.
+var ErrLinkPreview = errors.New("failed to fetch link preview")
+
+// urlPattern matches the http(s) URLs in a message text.
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// LinkPreview holds the OpenGraph metadata shown by WhatsApp below a message with a link.
+type LinkPreview struct {
+	MatchedText     string // URL as it appears in the message text
+	CanonicalURL    string // og:url of the page, or the fetched URL
+	Title           string // og:title, or the page <title>
+	Description     string // og:description, or the description meta tag
+	Thumbnail       []byte // JPEG thumbnail built from og:image, if any
+	ThumbnailWidth  int    // Width of the thumbnail in pixels
+	ThumbnailHeight int    // Height of the thumbnail in pixels
+}
+
+// LinkPreviewFetcher fetches link previews with a timeout and size caps.
+// The default HTTP client only connects to public addresses, as both the link and the og:image URL
+// come from untrusted input; it can be replaced, e.g. to point it at a local test server.
+type LinkPreviewFetcher struct {
+	Client        *http.Client  // HTTP client used for the page and image requests
+	Timeout       time.Duration // Time allowed for the whole preview (page and image)
+	MaxBytes      int64         // Largest part of the page read
+	MaxImageBytes int64         // Largest preview image downloaded
+}
+
+// defaultLinkPreviewFetcher is the fetcher used when sending messages with link previews.
+var defaultLinkPreviewFetcher = NewLinkPreviewFetcher()
+
+// NewLinkPreviewFetcher creates a fetcher with the default timeout and size caps.
+func NewLinkPreviewFetcher() *LinkPreviewFetcher {
+	return &LinkPreviewFetcher{
+		Client:        NewPublicHTTPClient(DefaultLinkPreviewTimeout),
+		Timeout:       DefaultLinkPreviewTimeout,
+		MaxBytes:      DefaultLinkPreviewMaxBytes,
+		MaxImageBytes: DefaultLinkPreviewMaxImageBytes,
+	}
+}
+
+// FindFirstURL returns the first http(s) URL in the text, or an empty string if there is none.
+func FindFirstURL(text string) string {
+	return strings.TrimRight(urlPattern.FindString(text), ".,;:!?)")
+}
+
+// Fetch downloads the page and builds its preview from the OpenGraph tags.
+// A missing or broken preview image is not an error: the preview is returned without a thumbnail.
+func (f *LinkPreviewFetcher) Fetch(ctx context.Context, rawURL string) (*LinkPreview, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, finalURL, err := f.get(ctx, rawURL, f.MaxBytes, "text/html")
+	if err != nil {
+		return nil, err
+	}
+
+	preview, imageURL := parseOpenGraph(body)
+	preview.MatchedText = rawURL
+	if preview.CanonicalURL == "" {
+		preview.CanonicalURL = finalURL.String()
+	}
+	if preview.Title == "" {
+		return nil, fmt.Errorf("%w: page has no title", ErrLinkPreview)
+	}
+
+	// Download the preview image, resolving it against the page URL.
+	if imageURL != "" {
+		if ref, err := finalURL.Parse(imageURL); err == nil {
+			if image, _, err := f.get(ctx, ref.String(), f.MaxImageBytes, "image/"); err == nil {
+				preview.Thumbnail, preview.ThumbnailWidth, preview.ThumbnailHeight, _ = GenerateJPEGThumbnail(image)
+			}
+		}
+	}
+
+	return preview, nil
+}
+
+// get performs a GET request and returns the body, capped at maxBytes, along with the final URL after redirects.
+// The response must have the expected content type prefix.
+func (f *LinkPreviewFetcher) get(ctx context.Context, rawURL string, maxBytes int64, contentType string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrLinkPreview, err)
+	}
+	req.Header.Set("User-Agent", "WhatsApp/2.0 (link preview)")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrLinkPreview, err)
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			fmt.Printf("Error closing link preview response body: %v\n", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%w: unexpected status code %d", ErrLinkPreview, resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), contentType) {
+		return nil, nil, fmt.Errorf("%w: unexpected content type %q", ErrLinkPreview, resp.Header.Get("Content-Type"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrLinkPreview, err)
+	}
+	return body, resp.Request.URL, nil
+}
+
+// parseOpenGraph reads the OpenGraph tags of the page head, falling back to the <title> element
+// and the description meta tag. It returns the preview and the og:image URL.
+func parseOpenGraph(body []byte) (*LinkPreview, string) {
+	preview := &LinkPreview{}
+	var imageURL, fallbackTitle, fallbackDescription string
+
+	tokenizer := html.NewTokenizer(strings.NewReader(string(body)))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		if tokenType == html.EndTagToken && token.Data == "head" || tokenType == html.StartTagToken && token.Data == "body" {
+			break
+		}
+
+		switch {
+		case tokenType == html.StartTagToken && token.Data == "title":
+			if tokenizer.Next() == html.TextToken {
+				fallbackTitle = strings.TrimSpace(string(tokenizer.Text()))
+			}
+		case token.Data == "meta":
+			attrs := make(map[string]string, len(token.Attr))
+			for _, attr := range token.Attr {
+				attrs[strings.ToLower(attr.Key)] = attr.Val
+			}
+			content := strings.TrimSpace(attrs["content"])
+
+			switch strings.ToLower(attrs["property"]) {
+			case "og:title":
+				preview.Title = content
+			case "og:description":
+				preview.Description = content
+			case "og:image", "og:image:url":
+				if imageURL == "" {
+					imageURL = content
+				}
+			case "og:url":
+				preview.CanonicalURL = content
+			}
+			if strings.ToLower(attrs["name"]) == "description" {
+				fallbackDescription = content
+			}
+		}
+	}
+
+	if preview.Title == "" {
+		preview.Title = fallbackTitle
+	}
+	if preview.Description == "" {
+		preview.Description = fallbackDescription
+	}
+	return preview, imageURL
+}
@@ -0,0 +1,183 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLinkPreviewServer starts a local server answering every path with the handler of the map.
+func newLinkPreviewServer(t *testing.T, pages map[string]http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	for path, handler := range pages {
+		mux.HandleFunc(path, handler)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newLocalLinkPreviewFetcher returns a fetcher allowed to reach the local test server.
+func newLocalLinkPreviewFetcher(server *httptest.Server) *LinkPreviewFetcher {
+	fetcher := NewLinkPreviewFetcher()
+	fetcher.Client = server.Client()
+	return fetcher
+}
+
+// htmlPage returns a handler serving the body as an HTML page.
+func htmlPage(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+func TestParseOpenGraph(t *testing.T) {
+	preview, imageURL := parseOpenGraph([]byte(`<html><head>
+		<title>Page title</title>
+		<meta property="og:title" content=" OpenGraph title ">
+		<meta property="og:description" content="OpenGraph description">
+		<meta property="og:image" content="/preview.png">
+		<meta property="og:image" content="/second.png">
+		<meta property="og:url" content="https://example.com/canonical">
+	</head><body><meta property="og:title" content="ignored"></body></html>`))
+
+	if preview.Title != "OpenGraph title" {
+		t.Errorf("Title = %q, want %q", preview.Title, "OpenGraph title")
+	}
+	if preview.Description != "OpenGraph description" {
+		t.Errorf("Description = %q", preview.Description)
+	}
+	if preview.CanonicalURL != "https://example.com/canonical" {
+		t.Errorf("CanonicalURL = %q", preview.CanonicalURL)
+	}
+	if imageURL != "/preview.png" {
+		t.Errorf("image URL = %q, want the first og:image", imageURL)
+	}
+}
+
+func TestParseOpenGraphFallbacks(t *testing.T) {
+	preview, imageURL := parseOpenGraph([]byte(`<html><head>
+		<title> Plain title </title>
+		<meta name="description" content="Plain description">
+	</head></html>`))
+
+	if preview.Title != "Plain title" {
+		t.Errorf("Title = %q, want the <title> fallback", preview.Title)
+	}
+	if preview.Description != "Plain description" {
+		t.Errorf("Description = %q, want the description meta fallback", preview.Description)
+	}
+	if imageURL != "" {
+		t.Errorf("image URL = %q, want none", imageURL)
+	}
+}
+
+func TestLinkPreviewFetchWithThumbnail(t *testing.T) {
+	var imageBytes bytes.Buffer
+	png.Encode(&imageBytes, image.NewRGBA(image.Rect(0, 0, 300, 200)))
+
+	server := newLinkPreviewServer(t, map[string]http.HandlerFunc{
+		"/page": htmlPage(`<head><meta property="og:title" content="Title"><meta property="og:image" content="/image.png"></head>`),
+		"/image.png": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(imageBytes.Bytes())
+		},
+	})
+
+	preview, err := newLocalLinkPreviewFetcher(server).Fetch(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if preview.Title != "Title" || preview.MatchedText != server.URL+"/page" || preview.CanonicalURL != server.URL+"/page" {
+		t.Errorf("preview = %+v", preview)
+	}
+	if len(preview.Thumbnail) == 0 || preview.ThumbnailWidth != 300 || preview.ThumbnailHeight != 200 {
+		t.Errorf("thumbnail of %d bytes, %dx%d, want a 300x200 thumbnail", len(preview.Thumbnail), preview.ThumbnailWidth, preview.ThumbnailHeight)
+	}
+}
+
+func TestLinkPreviewFetchWithBrokenThumbnail(t *testing.T) {
+	server := newLinkPreviewServer(t, map[string]http.HandlerFunc{
+		"/page": htmlPage(`<head><meta property="og:title" content="Title"><meta property="og:image" content="/missing.png"></head>`),
+	})
+
+	preview, err := newLocalLinkPreviewFetcher(server).Fetch(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if preview.Title != "Title" || preview.Thumbnail != nil {
+		t.Errorf("preview = %+v, want the title without a thumbnail", preview)
+	}
+}
+
+func TestLinkPreviewFetchBodySizeCap(t *testing.T) {
+	server := newLinkPreviewServer(t, map[string]http.HandlerFunc{
+		"/page": htmlPage(`<head><!--` + strings.Repeat("x", 1024) + `--><title>Late title</title></head>`),
+	})
+
+	fetcher := newLocalLinkPreviewFetcher(server)
+	fetcher.MaxBytes = 256
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/page"); !errors.Is(err, ErrLinkPreview) {
+		t.Fatalf("Fetch beyond the size cap = %v, want ErrLinkPreview", err)
+	}
+
+	fetcher.MaxBytes = DefaultLinkPreviewMaxBytes
+	if preview, err := fetcher.Fetch(context.Background(), server.URL+"/page"); err != nil || preview.Title != "Late title" {
+		t.Fatalf("Fetch within the size cap = %+v, %v", preview, err)
+	}
+}
+
+func TestLinkPreviewFetchTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := newLinkPreviewServer(t, map[string]http.HandlerFunc{
+		"/slow": func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		},
+	})
+	defer close(release)
+
+	fetcher := newLocalLinkPreviewFetcher(server)
+	fetcher.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/slow"); !errors.Is(err, ErrLinkPreview) {
+		t.Fatalf("Fetch = %v, want ErrLinkPreview", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch returned after %s, want about %s", elapsed, fetcher.Timeout)
+	}
+}
+
+func TestLinkPreviewFetchRejectsNonHTML(t *testing.T) {
+	server := newLinkPreviewServer(t, map[string]http.HandlerFunc{
+		"/data.json": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"title": "<title>Not a page</title>"}`))
+		},
+	})
+
+	if _, err := newLocalLinkPreviewFetcher(server).Fetch(context.Background(), server.URL+"/data.json"); !errors.Is(err, ErrLinkPreview) {
+		t.Fatalf("Fetch = %v, want ErrLinkPreview", err)
+	}
+}
+
+func TestLinkPreviewFetchRefusesInternalAddresses(t *testing.T) {
+	server := newLinkPreviewServer(t, map[string]http.HandlerFunc{
+		"/page": htmlPage(`<head><title>Internal page</title></head>`),
+	})
+
+	if _, err := NewLinkPreviewFetcher().Fetch(context.Background(), server.URL+"/page"); !errors.Is(err, ErrLinkPreview) || !strings.Contains(err.Error(), ErrForbiddenAddress.Error()) {
+		t.Fatalf("Fetch of an internal page = %v, want a refused address", err)
+	}
+}
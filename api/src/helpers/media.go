@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	// MaxMediaDownloadSize is the largest file accepted from a URL or base64 payload (100 MB).
+	MaxMediaDownloadSize = 100 << 20
+	// mediaDownloadTimeout is the timeout applied when fetching media from a URL.
+	mediaDownloadTimeout = 60 * time.Second
+)
+
+// Error definitions for media input issues.
+var (
+	ErrMediaDownload = errors.New("failed to download media")
+	ErrMediaDecode   = errors.New("failed to decode base64 media")
+	ErrMediaTooLarge = errors.New("media file is too large")
+)
+
+// MediaFile holds a media file received by the API before it is uploaded to WhatsApp.
+type MediaFile struct {
+	Data     []byte // Raw file content
+	FileName string // Original file name, if known
+	MimeType string // Mime type of the content
+}
+
+// mediaDownloadClient fetches media from caller-supplied URLs, refusing internal addresses.
+var mediaDownloadClient = NewPublicHTTPClient(mediaDownloadTimeout)
+
+// DownloadMedia fetches a media file from the given URL, limited to MaxMediaDownloadSize bytes.
+// Only http(s) URLs of public addresses are fetched, including after redirects.
+func DownloadMedia(url string) (*MediaFile, error) {
+	resp, err := mediaDownloadClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaDownload, err)
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			fmt.Printf("Error closing media response body: %v\n", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status code %d", ErrMediaDownload, resp.StatusCode)
+	}
+
+	// Read one byte past the limit so oversized files can be detected.
+	content, err := io.ReadAll(io.LimitReader(resp.Body, MaxMediaDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaDownload, err)
+	}
+	if len(content) > MaxMediaDownloadSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	return &MediaFile{
+		Data:     content,
+		FileName: path.Base(resp.Request.URL.Path),
+		MimeType: DetectMimeType(content, resp.Header.Get("Content-Type")),
+	}, nil
+}
+
+// DecodeBase64Media decodes a base64 media payload. Data URIs ("data:image/png;base64,...") are also accepted.
+func DecodeBase64Media(encoded string) (*MediaFile, error) {
+	var declaredType string
+	if strings.HasPrefix(encoded, "data:") {
+		header, payload, found := strings.Cut(encoded, ",")
+		if !found {
+			return nil, fmt.Errorf("%w: malformed data URI", ErrMediaDecode)
+		}
+		declaredType = strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+		encoded = payload
+	}
+
+	if base64.StdEncoding.DecodedLen(len(encoded)) > MaxMediaDownloadSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaDecode, err)
+	}
+
+	return &MediaFile{
+		Data:     content,
+		MimeType: DetectMimeType(content, declaredType),
+	}, nil
+}
+
+// DetectMimeType sniffs the mime type of the content, falling back to the declared type
+// when sniffing only yields a generic result.
+func DetectMimeType(content []byte, declared string) string {
+	detected := mimetype.Detect(content)
+	if declared != "" && (detected.Is("application/octet-stream") || detected.Is("text/plain")) {
+		return strings.TrimSpace(strings.Split(declared, ";")[0])
+	}
+	return strings.Split(detected.String(), ";")[0]
+}
+
+// MediaFileName returns the file name of the media, generating one from the mime type
+// (e.g. "document.pdf") when it is unknown.
+func MediaFileName(media *MediaFile, fallback string) string {
+	if media.FileName != "" && media.FileName != "." && media.FileName != "/" {
+		return media.FileName
+	}
+	if mime := mimetype.Lookup(media.MimeType); mime != nil {
+		return fallback + mime.Extension()
+	}
+	return fallback
+}
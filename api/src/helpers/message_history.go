@@ -0,0 +1,130 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ztrue/tracerr"
+
+	"whatsgoingon/data"
+	"whatsgoingon/handler"
+	"whatsgoingon/store"
+)
+
+const (
+	// DefaultHistoryPageSize is the number of messages returned per page when no limit is given.
+	DefaultHistoryPageSize = 50
+	// MaxHistoryPageSize is the largest page of messages that can be requested.
+	MaxHistoryPageSize = 200
+)
+
+// ErrInvalidHistoryQuery is returned when the filters or pagination of a history request are invalid.
+var ErrInvalidHistoryQuery = errors.New("invalid history query")
+
+// MessagePage is a page of the message history of a chat, newest messages first.
+type MessagePage struct {
+	Messages   []data.Message // Messages of the page
+	NextCursor string         // Cursor of the next (older) page, empty on the last page
+}
+
+// ListChats returns the chats of the device with their latest message and unread count.
+func ListChats(deviceID int) ([]data.Chat, error) {
+	chats, err := store.GetChats(deviceID)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return chats, nil
+}
+
+// GetChatMessages returns a page of the message history of a chat (phone number or JID) of the device.
+// The cursor, when given, is the NextCursor of the previous page.
+func GetChatMessages(deviceID int, chat string, cursor string, filter data.MessageFilter) (*MessagePage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryPageSize
+	}
+	if filter.Limit > MaxHistoryPageSize {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidHistoryQuery, MaxHistoryPageSize)
+	}
+
+	if cursor != "" {
+		before, err := data.ParseMessageCursor(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidHistoryQuery, err)
+		}
+		filter.Before = before
+	}
+
+	// Fetch one extra message to know whether there is a next page.
+	pageSize := filter.Limit
+	filter.Limit++
+
+	messages, err := store.GetChatMessages(deviceID, parseUserJID(chat).String(), filter)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	page := &MessagePage{Messages: messages}
+	if len(messages) > pageSize {
+		page.Messages = messages[:pageSize]
+		page.NextCursor = data.NewMessageCursor(page.Messages[pageSize-1]).Encode()
+	}
+	return page, nil
+}
+
+// SearchPage is a page of full-text search results, most relevant messages first.
+type SearchPage struct {
+	Results    []data.MessageSearchResult // Results of the page
+	NextOffset int                        // Offset of the next page, zero on the last page
+}
+
+// SearchMessages runs a full-text search over the message history of the device.
+// The chat and sender filters accept phone numbers or JIDs.
+func SearchMessages(deviceID int, chat string, sender string, search data.MessageSearch) (*SearchPage, error) {
+	if strings.TrimSpace(search.Query) == "" {
+		return nil, fmt.Errorf("%w: q is required", ErrInvalidHistoryQuery)
+	}
+	if search.Limit <= 0 {
+		search.Limit = DefaultHistoryPageSize
+	}
+	if search.Limit > MaxHistoryPageSize {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidHistoryQuery, MaxHistoryPageSize)
+	}
+	if search.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidHistoryQuery)
+	}
+	if search.Since != nil && search.Until != nil && !search.Since.Before(*search.Until) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidHistoryQuery)
+	}
+
+	if chat != "" {
+		search.ChatJID = parseUserJID(chat).String()
+	}
+	if sender != "" {
+		search.SenderJID = parseUserJID(sender).String()
+	}
+
+	// Fetch one extra result to know whether there is a next page.
+	pageSize := search.Limit
+	search.Limit++
+
+	results, err := store.SearchMessages(deviceID, search)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	page := &SearchPage{Results: results}
+	if len(results) > pageSize {
+		page.Results = results[:pageSize]
+		page.NextOffset = search.Offset + pageSize
+	}
+	return page, nil
+}
+
+// recordMessagesRead marks messages of a chat as read in the message history, logging any failure.
+func recordMessagesRead(deviceID int, chatJID string, messageIDs []string, readAt time.Time) {
+	if err := store.MarkMessagesRead(deviceID, chatJID, messageIDs, readAt); err != nil {
+		handler.FailOnError(err, "Failed to mark messages as read in the message history")
+	}
+}
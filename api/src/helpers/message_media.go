@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ztrue/tracerr"
+
+	"whatsgoingon/conf"
+	"whatsgoingon/data"
+	"whatsgoingon/handler"
+	"whatsgoingon/store"
+)
+
+// ErrMediaStoreUnavailable is returned when media is stored before the media store is initialised.
+var ErrMediaStoreUnavailable = errors.New("media store is not initialised")
+
+// StoreMessageMedia writes the media downloaded with a message to the media store and replaces the
+// inline content with its ID, size, SHA-256 and signed download URL. Contents already stored by a
+// previous message are not written again. On failure the content is left inline.
+func StoreMessageMedia(message *data.StoredMessage) error {
+	if len(message.Content) == 0 {
+		return nil
+	}
+
+	mediaStore := store.GetMediaStore()
+	if mediaStore == nil {
+		return tracerr.Wrap(ErrMediaStoreUnavailable)
+	}
+
+	sum := sha256.Sum256(message.Content)
+	digest := hex.EncodeToString(sum[:])
+
+	media, err := store.GetMediaBySHA256(digest)
+	if err != nil {
+		// Not stored yet: write the content, then record it.
+		storageKey := digest[:2] + "/" + digest
+		if err := mediaStore.Put(context.Background(), storageKey, message.Content, message.ContentMimeType); err != nil {
+			return tracerr.Wrap(err)
+		}
+
+		media, err = store.SaveMedia(&data.Media{
+			SHA256:     digest,
+			Size:       int64(len(message.Content)),
+			MimeType:   message.ContentMimeType,
+			StorageKey: storageKey,
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+
+	message.Media = SignedMediaInfo(media)
+	message.Content = nil
+	return nil
+}
+
+// SignedMediaInfo describes a stored media with a download URL valid for the configured expiration.
+// Media stores able to sign URLs (S3) are downloaded from directly; otherwise the URL points to the
+// media download route of the API.
+func SignedMediaInfo(media data.Media) *data.MediaInfo {
+	info := data.NewMediaInfo(media)
+
+	expiration := store.MediaURLExpiration()
+	expiresAt := time.Now().Add(expiration)
+	info.URLExpiresAt = &expiresAt
+
+	signer, ok := store.GetMediaStore().(store.MediaURLSigner)
+	if !ok {
+		info.URL = conf.SignedMediaURL(media.ID, expiresAt)
+		return info
+	}
+
+	signedURL, err := signer.SignedURL(media.StorageKey, expiration)
+	if err != nil {
+		handler.FailOnError(fmt.Errorf("failed to sign URL of media ID %d: %v", media.ID, err), "Failed to sign media URL")
+		info.URL = conf.SignedMediaURL(media.ID, expiresAt)
+		return info
+	}
+
+	info.URL = signedURL
+	return info
+}
+
+// OpenMedia returns a stored media and a reader over its content, which the caller must close.
+// Reads from the media store are cancelled with the context, e.g. when the client disconnects.
+func OpenMedia(ctx context.Context, mediaID int64) (data.Media, io.ReadSeekCloser, error) {
+	media, err := store.GetMediaByID(mediaID)
+	if err != nil {
+		return data.Media{}, nil, tracerr.Wrap(fmt.Errorf("%w: %v", store.ErrMediaNotFound, err))
+	}
+
+	mediaStore := store.GetMediaStore()
+	if mediaStore == nil {
+		return data.Media{}, nil, tracerr.Wrap(ErrMediaStoreUnavailable)
+	}
+
+	content, err := mediaStore.Open(ctx, media.StorageKey)
+	if err != nil {
+		return data.Media{}, nil, tracerr.Wrap(err)
+	}
+	return media, content, nil
+}
@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ztrue/tracerr"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"whatsgoingon/data"
+	"whatsgoingon/handler"
+	"whatsgoingon/store"
+)
+
+// ErrMessageStatusNotFound is returned when no status is known for a message.
+var ErrMessageStatusNotFound = errors.New("message status not found")
+
+// receiptStatuses maps the receipt types that report progress on our messages to their status.
+var receiptStatuses = map[types.ReceiptType]string{
+	types.ReceiptTypeDelivered: data.MessageStatusDelivered,
+	types.ReceiptTypeRead:      data.MessageStatusRead,
+	types.ReceiptTypePlayed:    data.MessageStatusPlayed,
+}
+
+// recordMessageSent stores the SENT status of a message just sent by the device, logging any failure.
+// For groups the row is recorded against the group itself, as receipts only arrive per participant;
+// it gives the message a status until the first receipt and is not listed as a recipient.
+func recordMessageSent(deviceID int, messageID string, destination types.JID, timestamp time.Time) {
+	status := data.NewMessageStatus(deviceID, messageID, destination.String(), destination.String(), data.MessageStatusSent, timestamp)
+	if _, err := store.UpsertMessageStatus(status); err != nil {
+		handler.FailOnError(err, "Failed to record sent message status")
+	}
+}
+
+// RecordReceipt stores the status reported by a delivery, read or played receipt for each message it covers.
+// It returns the status changes that advanced a message, which are the ones worth notifying.
+// Read receipts sent by another device of the account mark the messages as read in the message history.
+func RecordReceipt(deviceID int, receipt *events.Receipt) []data.MessageStatusEvent {
+	if receipt.IsFromMe {
+		if receipt.Type == types.ReceiptTypeRead || receipt.Type == types.ReceiptTypeReadSelf {
+			recordMessagesRead(deviceID, receipt.Chat.String(), receipt.MessageIDs, receipt.Timestamp)
+		}
+		return nil
+	}
+
+	// Only receipts from other users about our own messages are tracked.
+	status, ok := receiptStatuses[receipt.Type]
+	if !ok {
+		return nil
+	}
+
+	recipient := receipt.Sender.ToNonAD()
+	if !receipt.IsGroup {
+		recipient = receipt.Chat.ToNonAD()
+	}
+
+	var changes []data.MessageStatusEvent
+	for _, messageID := range receipt.MessageIDs {
+		messageStatus := data.NewMessageStatus(deviceID, messageID, receipt.Chat.String(), recipient.String(), status, receipt.Timestamp)
+		advanced, err := store.UpsertMessageStatus(messageStatus)
+		if err != nil {
+			handler.FailOnError(err, "Failed to record message receipt")
+			continue
+		}
+		if !advanced {
+			continue
+		}
+
+		changes = append(changes, data.MessageStatusEvent{
+			EventType:    data.EventTypeMessageStatus,
+			MessageID:    messageID,
+			ChatJID:      messageStatus.ChatJID,
+			RecipientJID: messageStatus.RecipientJID,
+			Status:       status,
+			Timestamp:    receipt.Timestamp,
+		})
+	}
+	return changes
+}
+
+// GetMessageStatus returns the status of a sent message across its recipients.
+func GetMessageStatus(deviceID int, messageID string) (*data.MessageStatusResponse, error) {
+	statuses, err := store.GetMessageStatuses(deviceID, messageID)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMessageStatusNotFound, messageID)
+	}
+
+	// Statuses are ordered from the most advanced one.
+	return &data.MessageStatusResponse{
+		MessageID:  messageID,
+		ChatJID:    statuses[0].ChatJID,
+		Status:     statuses[0].Status,
+		Recipients: recipientStatuses(statuses),
+	}, nil
+}
+
+// recipientStatuses returns the statuses reported by actual recipients, leaving out the SENT row that
+// recordMessageSent keeps against the group JID for messages sent to a group.
+func recipientStatuses(statuses []data.MessageStatus) []data.MessageStatus {
+	recipients := make([]data.MessageStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if chat, err := types.ParseJID(status.ChatJID); err == nil && chat.Server == types.GroupServer && status.RecipientJID == status.ChatJID {
+			continue
+		}
+		recipients = append(recipients, status)
+	}
+	return recipients
+}
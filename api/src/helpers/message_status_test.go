@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"whatsgoingon/data"
+)
+
+func TestRecipientStatusesLeavesOutGroupSentRow(t *testing.T) {
+	now := time.Now()
+	group := "120363000000000000@g.us"
+	statuses := []data.MessageStatus{
+		*data.NewMessageStatus(1, "MSG", group, "5511911111111@s.whatsapp.net", data.MessageStatusRead, now),
+		*data.NewMessageStatus(1, "MSG", group, group, data.MessageStatusSent, now),
+	}
+
+	recipients := recipientStatuses(statuses)
+	if len(recipients) != 1 || recipients[0].RecipientJID != "5511911111111@s.whatsapp.net" {
+		t.Fatalf("recipients = %+v, want only the participant", recipients)
+	}
+}
+
+func TestRecipientStatusesKeepsDirectChatRecipient(t *testing.T) {
+	chat := "5511911111111@s.whatsapp.net"
+	statuses := []data.MessageStatus{*data.NewMessageStatus(1, "MSG", chat, chat, data.MessageStatusSent, time.Now())}
+
+	if recipients := recipientStatuses(statuses); len(recipients) != 1 {
+		t.Fatalf("recipients = %+v, want the direct chat recipient", recipients)
+	}
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const (
+	// publicDialTimeout is the time allowed to open a connection to a public address.
+	publicDialTimeout = 10 * time.Second
+	// maxPublicRedirects is the number of redirects followed by the public HTTP client.
+	maxPublicRedirects = 10
+)
+
+// ErrForbiddenAddress is returned when a URL supplied by a caller resolves to an internal address.
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// nonPublicPrefixes lists the ranges that are not reachable on the public internet and must not be
+// fetched on behalf of callers: private networks, shared (carrier-grade NAT) space, which hosts some
+// cloud metadata services, and reserved ranges. Loopback, link-local (including 169.254.169.254),
+// multicast and unspecified addresses are rejected by isPublicAddress itself.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // Shared address space (carrier-grade NAT)
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+	netip.MustParsePrefix("fec0::/10"),      // Deprecated site-local
+}
+
+// isPublicAddress reports whether the address is publicly routable.
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicDialControl refuses connections to non-public addresses. It runs after DNS resolution for every
+// connection, so host names resolving to internal addresses and redirects to them are refused too.
+func publicDialControl(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !isPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// checkPublicRedirect only follows redirects to http(s) URLs, up to maxPublicRedirects.
+func checkPublicRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxPublicRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxPublicRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("%w: redirect to %s URL", ErrForbiddenAddress, req.URL.Scheme)
+	}
+	return nil
+}
+
+// NewPublicHTTPClient returns an HTTP client for fetching URLs supplied by callers or found in
+// untrusted content. It only connects to public addresses, on the first request and on every redirect,
+// and ignores proxy settings so the address check applies to the actual destination.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: publicDialTimeout, Control: publicDialControl}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport:     transport,
+		Timeout:       timeout,
+		CheckRedirect: checkPublicRedirect,
+	}
+}
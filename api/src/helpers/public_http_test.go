@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":                true,
+		"2606:4700::1111":        true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.100.100.200":        false,
+		"0.0.0.0":                false,
+		"::":                     false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"224.0.0.1":              false,
+		"255.255.255.255":        false,
+	}
+
+	for address, want := range tests {
+		if got := isPublicAddress(netip.MustParseAddr(address)); got != want {
+			t.Errorf("isPublicAddress(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestPublicHTTPClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	// The host name is resolved before the check, so "localhost" is refused like 127.0.0.1.
+	serverURL, _ := url.Parse(server.URL)
+	for _, target := range []string{server.URL, "http://localhost:" + serverURL.Port()} {
+		_, err := NewPublicHTTPClient(time.Second).Get(target)
+		if !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("GET %s = %v, want ErrForbiddenAddress", target, err)
+		}
+	}
+
+	if _, err := DownloadMedia(server.URL + "/image.png"); !errors.Is(err, ErrMediaDownload) {
+		t.Errorf("DownloadMedia of an internal URL = %v, want ErrMediaDownload", err)
+	}
+}
+
+func TestCheckPublicRedirect(t *testing.T) {
+	redirect := &http.Request{URL: &url.URL{Scheme: "file", Path: "/etc/passwd"}}
+	if err := checkPublicRedirect(redirect, nil); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("redirect to file URL = %v, want ErrForbiddenAddress", err)
+	}
+
+	redirect = &http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}}
+	if err := checkPublicRedirect(redirect, nil); err != nil {
+		t.Errorf("redirect to https URL = %v, want nil", err)
+	}
+	if err := checkPublicRedirect(redirect, make([]*http.Request, maxPublicRedirects)); err == nil {
+		t.Error("redirects past the limit must be refused")
+	}
+}
@@ -2,6 +2,9 @@ package helpers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"sync"
@@ -16,6 +19,10 @@ import (
 const (
 	// WhatsAppMessageContentList represents the Redis database index to use.
 	WhatsAppMessageContentList = 0
+	// redisPresenceKey is the hash holding the last known presence of each contact of a device.
+	redisPresenceKey = "presence:%d"
+	// redisPresenceSubscriptionsKey is the set of contacts whose presence a device is subscribed to.
+	redisPresenceSubscriptionsKey = "presence:%d:subscribed"
 )
 
 var (
@@ -59,9 +66,9 @@ func PingRedis(ctx context.Context) error {
 	return client.Ping(ctx).Err() // Send a ping to Redis and return any errors.
 }
 
-// SendMessageToRedis pushes a message to a Redis list for a given device.
+// SendMessageToRedis pushes a message (a stored message or any other event) to a Redis list for a given device.
 // The message is first marshaled to JSON before being sent.
-func SendMessageToRedis(ctx context.Context, content data.StoredMessage, deviceID int) {
+func SendMessageToRedis(ctx context.Context, content interface{}, deviceID int) {
 	// Ping Redis to ensure it's reachable.
 	if err := PingRedis(ctx); err != nil {
 		handler.FailOnError(err, "Failed to ping Redis server")
@@ -87,3 +94,65 @@ func SendMessageToRedis(ctx context.Context, content data.StoredMessage, deviceI
 	// Log the success of the operation.
 	log.Printf("Message sent to Redis successfully for deviceID: %d", deviceID)
 }
+
+// SavePresenceToRedis stores the last known presence of a contact and returns the previous one, if any.
+func SavePresenceToRedis(ctx context.Context, deviceID int, presence data.ContactPresence) (*data.ContactPresence, error) {
+	client := getRedisClient()
+	key := fmt.Sprintf(redisPresenceKey, deviceID)
+
+	previous, err := GetPresenceFromRedis(ctx, deviceID, presence.JID)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonContent, err := MarshalMessageToJSON(presence)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.HSet(ctx, key, presence.JID, jsonContent).Err(); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// GetPresenceFromRedis returns the last known presence of a contact, or nil when none was received yet.
+func GetPresenceFromRedis(ctx context.Context, deviceID int, jid string) (*data.ContactPresence, error) {
+	client := getRedisClient()
+
+	content, err := client.HGet(ctx, fmt.Sprintf(redisPresenceKey, deviceID), jid).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var presence data.ContactPresence
+	if err := json.Unmarshal(content, &presence); err != nil {
+		return nil, err
+	}
+	return &presence, nil
+}
+
+// AddPresenceSubscriptions records the contacts whose presence the device is subscribed to.
+func AddPresenceSubscriptions(ctx context.Context, deviceID int, jids []string) error {
+	client := getRedisClient()
+
+	members := make([]interface{}, len(jids))
+	for i, jid := range jids {
+		members[i] = jid
+	}
+	return client.SAdd(ctx, fmt.Sprintf(redisPresenceSubscriptionsKey, deviceID), members...).Err()
+}
+
+// GetPresenceSubscriptions returns the contacts whose presence the device is subscribed to.
+func GetPresenceSubscriptions(ctx context.Context, deviceID int) ([]string, error) {
+	client := getRedisClient()
+	return client.SMembers(ctx, fmt.Sprintf(redisPresenceSubscriptionsKey, deviceID)).Result()
+}
+
+// IsPresenceSubscribed reports whether the device is subscribed to the presence of the contact.
+func IsPresenceSubscribed(ctx context.Context, deviceID int, jid string) (bool, error) {
+	client := getRedisClient()
+	return client.SIsMember(ctx, fmt.Sprintf(redisPresenceSubscriptionsKey, deviceID), jid).Result()
+}
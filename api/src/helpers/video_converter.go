@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"math"
+	"strconv"
+)
+
+const (
+	// MaxVideoSize is the largest video WhatsApp accepts as a video message (16 MB).
+	MaxVideoSize = 16 << 20
+)
+
+// VideoInfo holds the metadata WhatsApp needs to display a video message.
+type VideoInfo struct {
+	Width     uint32 // Width of the video in pixels
+	Height    uint32 // Height of the video in pixels
+	Seconds   uint32 // Duration of the video in seconds
+	Thumbnail []byte // JPEG thumbnail extracted from the first frame
+}
+
+// ffprobeOutput is the subset of the ffprobe JSON output used by ProbeVideo.
+type ffprobeOutput struct {
+	Streams []struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// ProbeVideo reads the dimensions and duration of a video and extracts a JPEG thumbnail from its first frame.
+// Probing stops when the context is cancelled.
+func ProbeVideo(ctx context.Context, fileBytes []byte) (*VideoInfo, error) {
+	// Read the dimensions of the first video stream and the container duration.
+	output, err := runFFprobe(ctx, fileBytes,
+		"-select_streams", "v:0",
+		"-show_entries", "stream=width,height:format=duration",
+		"-of", "json",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("%w: invalid ffprobe output: %v", ErrMediaConversion, err)
+	}
+	if len(probe.Streams) == 0 {
+		return nil, fmt.Errorf("%w: no video stream found", ErrMediaConversion)
+	}
+
+	info := &VideoInfo{
+		Width:  uint32(probe.Streams[0].Width),
+		Height: uint32(probe.Streams[0].Height),
+	}
+	if duration, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Seconds = uint32(math.Ceil(duration))
+	}
+
+	// Extract the first frame as JPEG and scale it down to a thumbnail.
+	frame, err := runFFmpeg(ctx, fileBytes, "-frames:v", "1", "-f", "image2pipe", "-vcodec", "mjpeg", "pipe:1")
+	if err != nil {
+		return nil, err
+	}
+	img, err := jpeg.Decode(bytes.NewReader(frame))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid video frame: %v", ErrMediaConversion, err)
+	}
+	if info.Thumbnail, err = EncodeJPEGThumbnail(img); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
@@ -63,9 +63,9 @@ func AllMessagesNon200(messages []data.WebhookMessage) bool {
 	return true
 }
 
-// SendWebhook sends a webhook message to the specified URL and logs the response in the database.
-// It only sends the message if the webhook URL is active.
-func SendWebhook(message data.StoredMessage, deviceID int, webhookURL string, webhookActive bool) {
+// SendWebhook sends a webhook message (a stored message or any other event) to the specified URL
+// and logs the response in the database. It only sends the message if the webhook URL is active.
+func SendWebhook(message interface{}, deviceID int, webhookURL string, webhookActive bool) {
 	if webhookURL == "" || !webhookActive {
 		return
 	}
@@ -5,8 +5,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
-	"sync"
+	"strings"
 	"time"
 
 	"whatsgoingon/handler"
@@ -23,13 +22,16 @@ import (
 
 // Error definitions for database and WhatsApp client issues.
 var (
-	dbLog                 = waLog.Stdout("Database", "WARN", true)
 	wmLog                 = waLog.Stdout("WhatsMeow", "WARN", true)
-	dbMutex               sync.Mutex
 	ErrDBConnectionFailed = errors.New("failed to connect to the database")
 	ErrDeviceNotFound     = errors.New("device not found in the store")
 	ErrClientConnection   = errors.New("failed to connect the WhatsApp client")
 	ErrMessageSending     = errors.New("failed to send the message")
+	ErrEditWindowExpired  = errors.New("message can no longer be edited")
+	ErrMessageNotFromMe   = errors.New("only messages sent by the device can be edited")
+	ErrPollNotFound       = errors.New("poll not found")
+	ErrInvalidRecipient   = errors.New("invalid recipient JID")
+	ErrNotGroupMember     = errors.New("device is not a participant of the group")
 )
 
 // DeviceResponse represents the device information returned in API responses.
@@ -51,19 +53,9 @@ type DeviceInfoResponse struct {
 	BusinessName string `json:"business_name"`
 }
 
-// connectToDatabase establishes a connection to the PostgreSQL database and returns a WhatsMeow container.
-func connectToDatabase() (*sqlstore.Container, error) {
-	dbUser := os.Getenv("PG_USERNAME")
-	dbPwd := os.Getenv("PG_PASSWORD")
-	dbTCPHost := os.Getenv("PG_HOSTNAME")
-	dbPort := os.Getenv("PG_PORT")
-	dbName := os.Getenv("PG_DATABASE")
-	dbSchema := os.Getenv("PG_WM_SCHEMA")
-
-	dbURI := fmt.Sprintf("host=%s user=%s password=%s port=%s database=%s search_path=%s sslmode=disable",
-		dbTCPHost, dbUser, dbPwd, dbPort, dbName, dbSchema)
-
-	container, err := sqlstore.New("pgx", dbURI, dbLog)
+// getContainer returns the shared WhatsMeow container initialised at startup.
+func getContainer() (*sqlstore.Container, error) {
+	container, err := store.GetWhatsmeowContainer()
 	if err != nil {
 		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrDBConnectionFailed, err))
 	}
@@ -72,57 +64,28 @@ func connectToDatabase() (*sqlstore.Container, error) {
 
 // GetDeviceStoreByJID retrieves the device store (store.Device) by its JID (WhatsApp ID).
 func GetDeviceStoreByJID(whatsappID string) (*waStore.Device, error) {
-	dbMutex.Lock()
-	defer dbMutex.Unlock()
-
-	container, err := connectToDatabase() // Conexão com o banco de dados
+	container, err := getContainer()
 	if err != nil {
 		return nil, err
 	}
 
 	jid, _ := types.ParseJID(whatsappID)
 
-	deviceStore, err := container.GetDevice(jid) // Supondo que retorna *store.Device
+	deviceStore, err := container.GetDevice(jid)
 	if err != nil {
 		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrDeviceNotFound, err))
 	}
-
-	return deviceStore, nil
-}
-
-// GetWhatsAppClientByDeviceStore retrieves a WhatsApp client using the store.Device.
-func GetWhatsAppClientByDeviceStore(deviceStore *waStore.Device) (*whatsmeow.Client, error) {
-	client := whatsmeow.NewClient(deviceStore, wmLog) // Passa o store.Device
-
-	if client.Store.ID == nil {
-		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrClientConnection, client.Store.ID))
+	if deviceStore == nil {
+		return nil, tracerr.Wrap(fmt.Errorf("%w: %s", ErrDeviceNotFound, whatsappID))
 	}
 
-	if !client.IsConnected() {
-		err := client.Connect()
-		if err != nil {
-			return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrClientConnection, err))
-		}
-	}
-
-	return client, nil
+	return deviceStore, nil
 }
 
-// GetWhatsAppClientByJID retrieves a WhatsApp client by its JID (WhatsApp ID).
+// GetWhatsAppClientByJID retrieves the shared, connected WhatsApp client by its JID (WhatsApp ID).
+// Clients are owned by the ClientManager and must not be disconnected by callers.
 func GetWhatsAppClientByJID(whatsappID string) (*whatsmeow.Client, error) {
-	// Obter o device store
-	deviceStore, err := GetDeviceStoreByJID(whatsappID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Obter o client usando o device store
-	client, err := GetWhatsAppClientByDeviceStore(deviceStore)
-	if err != nil {
-		return nil, err
-	}
-
-	return client, nil
+	return GetClientManager().Get(whatsappID)
 }
 
 // GetWhatsappClientByDeviceID returns a WhatsApp client using the device's ID.
@@ -136,7 +99,7 @@ func GetWhatsappClientByDeviceID(deviceID int) (*whatsmeow.Client, error) {
 
 // GetAllWhatsappIDs retrieves all WhatsApp IDs from the database.
 func GetAllWhatsappIDs() ([]string, error) {
-	container, err := connectToDatabase()
+	container, err := getContainer()
 	if err != nil {
 		return nil, err
 	}
@@ -156,7 +119,7 @@ func GetAllWhatsappIDs() ([]string, error) {
 
 // NewClient creates a new WhatsApp client instance.
 func NewClient() (*whatsmeow.Client, error) {
-	container, err := connectToDatabase()
+	container, err := getContainer()
 	if err != nil {
 		return nil, err
 	}
@@ -169,10 +132,7 @@ func NewClient() (*whatsmeow.Client, error) {
 
 // GetDeviceList retrieves a list of all registered devices and their associated information.
 func GetDeviceList() ([]DeviceResponse, error) {
-	dbMutex.Lock()
-	defer dbMutex.Unlock()
-
-	container, err := connectToDatabase()
+	container, err := getContainer()
 	if err != nil {
 		return nil, err
 	}
@@ -206,6 +166,9 @@ func GetDeviceList() ([]DeviceResponse, error) {
 
 // LogoutDeviceByJID logs out and removes a device from the store by its JID.
 func LogoutDeviceByJID(jid string) {
+	// Drop the shared client so no route keeps using a logged out session.
+	GetClientManager().Remove(jid)
+
 	device, err := store.GetDeviceByJID(jid)
 	if err != nil {
 		return
@@ -228,6 +191,42 @@ func CheckIfNumberExistsAndGetJID(number string, client *whatsmeow.Client) (type
 	return types.JID{}, fmt.Errorf("number is not registered in WhatsApp: %v", number)
 }
 
+// ResolveRecipientJID resolves the JID to send a message to. The recipient can be a phone number or a full JID:
+// phone numbers and user JIDs are checked with IsOnWhatsApp, groups must be joined by the device and
+// other servers (newsletters, broadcast lists, ...) are used as given.
+func ResolveRecipientJID(recipient string, client *whatsmeow.Client) (types.JID, error) {
+	if !strings.Contains(recipient, "@") {
+		return CheckIfNumberExistsAndGetJID(recipient, client)
+	}
+
+	jid, err := types.ParseJID(recipient)
+	if err != nil || jid.User == "" {
+		return types.JID{}, fmt.Errorf("%w: %s", ErrInvalidRecipient, recipient)
+	}
+
+	switch jid.Server {
+	case types.DefaultUserServer, types.LegacyUserServer:
+		return CheckIfNumberExistsAndGetJID("+"+jid.User, client)
+	case types.GroupServer:
+		return jid, checkJoinedGroup(jid, client)
+	default:
+		return jid, nil
+	}
+}
+
+// checkJoinedGroup verifies that the device is a participant of the group.
+// Only the info of that group is queried, which WhatsApp refuses when the device is not in it.
+func checkJoinedGroup(group types.JID, client *whatsmeow.Client) error {
+	_, err := client.GetGroupInfo(group)
+	if errors.Is(err, whatsmeow.ErrNotInGroup) || errors.Is(err, whatsmeow.ErrGroupNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotGroupMember, group)
+	}
+	if err != nil {
+		return tracerr.Wrap(fmt.Errorf("error retrieving group info: %v", err))
+	}
+	return nil
+}
+
 // GenerateQRCode generates a QR code in base64-encoded format.
 func GenerateQRCode(qrCode string) (string, error) {
 	png, err := qrcode.Encode(qrCode, qrcode.Medium, 256)
@@ -0,0 +1,162 @@
+package helpers
+
+import (
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"whatsgoingon/data"
+	"whatsgoingon/store"
+)
+
+// SendOptions holds the optional settings shared by every message sent through the API.
+type SendOptions struct {
+	QuotedMessageID string        // ID of the message being replied to
+	QuotedSender    string        // Phone number or JID of the author of the quoted message
+	Mentions        []string      // Phone numbers or JIDs of the users mentioned in the text
+	LinkPreview     bool          // Fetch and attach the preview of the first link (text messages only)
+	TypingDuration  time.Duration // Show "typing..." for this long before sending
+}
+
+// buildContextInfo builds the ContextInfo for the message according to the send options.
+// It returns nil when the message does not need any context.
+func buildContextInfo(client *whatsmeow.Client, deviceJID string, destination types.JID, opts SendOptions) *waE2E.ContextInfo {
+	if opts.QuotedMessageID == "" && len(opts.Mentions) == 0 {
+		return nil
+	}
+
+	contextInfo := &waE2E.ContextInfo{}
+
+	if opts.QuotedMessageID != "" {
+		// Try to resolve the original message from the stored history.
+		quoted := findStoredMessage(deviceJID, opts.QuotedMessageID)
+
+		contextInfo.StanzaID = proto.String(opts.QuotedMessageID)
+		contextInfo.Participant = proto.String(resolveMessageAuthor(client, destination, opts.QuotedSender, quoted).String())
+		if quoted != nil {
+			contextInfo.QuotedMessage = quotedMessageFromStored(quoted)
+		}
+	}
+
+	// WhatsApp highlights the "@number" occurrences in the text for the mentioned JIDs.
+	for _, mention := range opts.Mentions {
+		contextInfo.MentionedJID = append(contextInfo.MentionedJID, parseUserJID(mention).String())
+	}
+
+	return contextInfo
+}
+
+// findStoredMessage looks up a message of the device in the stored history, returning nil when unavailable.
+func findStoredMessage(deviceJID string, messageID string) *data.StoredMessage {
+	device, err := store.GetDeviceByJID(deviceJID)
+	if err != nil {
+		return nil
+	}
+
+	message, err := store.GetMessageByID(device.ID, messageID)
+	if err != nil {
+		return nil
+	}
+	return message.ToStoredMessage()
+}
+
+// resolveMessageAuthor resolves the JID of the author of a referenced (quoted or reacted) message.
+// An explicit sender wins, then the stored message author; otherwise the chat itself is assumed.
+func resolveMessageAuthor(client *whatsmeow.Client, destination types.JID, sender string, referenced *data.StoredMessage) types.JID {
+	if sender != "" {
+		return parseUserJID(sender)
+	}
+
+	if referenced != nil {
+		if referenced.IsFromMe {
+			return client.Store.ID.ToNonAD()
+		}
+		if jid, err := types.ParseJID(referenced.SenderJID); err == nil && !jid.IsEmpty() {
+			return jid
+		}
+	}
+
+	return destination
+}
+
+// parseUserJID converts a phone number (digits with optional "+") or a full JID into a user JID.
+func parseUserJID(value string) types.JID {
+	if strings.Contains(value, "@") {
+		if jid, err := types.ParseJID(value); err == nil {
+			return jid.ToNonAD()
+		}
+	}
+	return types.NewJID(strings.TrimPrefix(value, "+"), types.DefaultUserServer)
+}
+
+// quotedMessageFromStored rebuilds a minimal WhatsApp message from a stored message,
+// which is enough for WhatsApp to render the quoted preview.
+func quotedMessageFromStored(quoted *data.StoredMessage) *waE2E.Message {
+	mimetype := proto.String(quoted.ContentMimeType)
+	switch quoted.MediaType {
+	case "IMAGE":
+		return &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String(quoted.Text), Mimetype: mimetype}}
+	case "VIDEO":
+		return &waE2E.Message{VideoMessage: &waE2E.VideoMessage{Caption: proto.String(quoted.Text), Mimetype: mimetype}}
+	case "AUDIO":
+		return &waE2E.Message{AudioMessage: &waE2E.AudioMessage{Mimetype: mimetype}}
+	case "STICKER":
+		return &waE2E.Message{StickerMessage: &waE2E.StickerMessage{Mimetype: mimetype}}
+	case "LOCATION":
+		if quoted.Location != nil {
+			return &waE2E.Message{LocationMessage: &waE2E.LocationMessage{
+				DegreesLatitude:  proto.Float64(quoted.Location.Latitude),
+				DegreesLongitude: proto.Float64(quoted.Location.Longitude),
+				Name:             proto.String(quoted.Location.Name),
+			}}
+		}
+		return &waE2E.Message{Conversation: proto.String(quoted.Text)}
+	case "DOCUMENT":
+		return &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{Caption: proto.String(quoted.Text), Mimetype: mimetype}}
+	default:
+		return &waE2E.Message{Conversation: proto.String(quoted.Text)}
+	}
+}
+
+// applyContextInfo attaches the context info to whichever content the message carries.
+// Plain conversation messages are upgraded to extended text messages, which support context info.
+func applyContextInfo(message *waE2E.Message, contextInfo *waE2E.ContextInfo) {
+	if contextInfo == nil {
+		return
+	}
+
+	switch {
+	case message.Conversation != nil:
+		message.ExtendedTextMessage = &waE2E.ExtendedTextMessage{Text: message.Conversation}
+		message.Conversation = nil
+		message.ExtendedTextMessage.ContextInfo = contextInfo
+	case message.ExtendedTextMessage != nil:
+		message.ExtendedTextMessage.ContextInfo = contextInfo
+	case message.ImageMessage != nil:
+		message.ImageMessage.ContextInfo = contextInfo
+	case message.VideoMessage != nil:
+		message.VideoMessage.ContextInfo = contextInfo
+	case message.AudioMessage != nil:
+		message.AudioMessage.ContextInfo = contextInfo
+	case message.DocumentMessage != nil:
+		message.DocumentMessage.ContextInfo = contextInfo
+	case message.StickerMessage != nil:
+		message.StickerMessage.ContextInfo = contextInfo
+	case message.LocationMessage != nil:
+		message.LocationMessage.ContextInfo = contextInfo
+	case message.ContactMessage != nil:
+		message.ContactMessage.ContextInfo = contextInfo
+	case message.ContactsArrayMessage != nil:
+		message.ContactsArrayMessage.ContextInfo = contextInfo
+	case message.PollCreationMessage != nil:
+		message.PollCreationMessage.ContextInfo = contextInfo
+	case message.PollCreationMessageV2 != nil:
+		message.PollCreationMessageV2.ContextInfo = contextInfo
+	case message.PollCreationMessageV3 != nil:
+		message.PollCreationMessageV3.ContextInfo = contextInfo
+	}
+}
@@ -0,0 +1,20 @@
+package helpers
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestApplyContextInfoToPoll(t *testing.T) {
+	var client *whatsmeow.Client
+	poll := client.BuildPollCreation("Lunch?", []string{"Yes", "No"}, 1)
+	contextInfo := &waE2E.ContextInfo{StanzaID: proto.String("QUOTED-ID"), MentionedJID: []string{testChatJID.String()}}
+
+	applyContextInfo(poll, contextInfo)
+	if got := poll.GetPollCreationMessage().GetContextInfo(); got != contextInfo {
+		t.Fatalf("poll context info = %v, want the quoted message and mentions", got)
+	}
+}
@@ -0,0 +1,261 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ztrue/tracerr"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Error definitions for group management issues.
+var (
+	ErrInvalidGroupJID       = errors.New("invalid group JID")
+	ErrInvalidGroupAction    = errors.New("invalid participant action")
+	ErrGroupOperationFailure = errors.New("group operation failed")
+)
+
+// GroupParticipantResponse represents a participant of a group in API responses.
+type GroupParticipantResponse struct {
+	JID          string `json:"jid"`             // JID of the participant
+	PhoneNumber  string `json:"phone_number"`    // Phone number of the participant
+	IsAdmin      bool   `json:"is_admin"`        // Whether the participant is an admin
+	IsSuperAdmin bool   `json:"is_super_admin"`  // Whether the participant is the group creator
+	Error        int    `json:"error,omitempty"` // Error code returned by WhatsApp when a participant change failed
+}
+
+// GroupResponse represents a WhatsApp group in API responses.
+type GroupResponse struct {
+	JID          string                     `json:"jid"`                    // JID of the group
+	Name         string                     `json:"name"`                   // Group name (subject)
+	Description  string                     `json:"description"`            // Group description (topic)
+	OwnerJID     string                     `json:"owner_jid"`              // JID of the group creator
+	IsAnnounce   bool                       `json:"is_announce"`            // Only admins can send messages
+	IsLocked     bool                       `json:"is_locked"`              // Only admins can edit the group info
+	CreatedAt    time.Time                  `json:"created_at"`             // Timestamp when the group was created
+	Participants []GroupParticipantResponse `json:"participants,omitempty"` // Participants of the group
+}
+
+// groupParticipantActions maps the actions accepted by the API to WhatsMeow participant changes.
+var groupParticipantActions = map[string]whatsmeow.ParticipantChange{
+	"add":     whatsmeow.ParticipantChangeAdd,
+	"remove":  whatsmeow.ParticipantChangeRemove,
+	"promote": whatsmeow.ParticipantChangePromote,
+	"demote":  whatsmeow.ParticipantChangeDemote,
+}
+
+// ParseGroupJID parses a group JID; the "@g.us" suffix is optional.
+func ParseGroupJID(group string) (types.JID, error) {
+	if !strings.Contains(group, "@") {
+		group += "@" + types.GroupServer
+	}
+	jid, err := types.ParseJID(group)
+	if err != nil || jid.Server != types.GroupServer || jid.User == "" {
+		return types.JID{}, fmt.Errorf("%w: %s", ErrInvalidGroupJID, group)
+	}
+	return jid, nil
+}
+
+// newGroupResponse converts WhatsMeow group info into its API representation.
+func newGroupResponse(info *types.GroupInfo, withParticipants bool) GroupResponse {
+	group := GroupResponse{
+		JID:         info.JID.String(),
+		Name:        info.Name,
+		Description: info.Topic,
+		OwnerJID:    info.OwnerJID.String(),
+		IsAnnounce:  info.IsAnnounce,
+		IsLocked:    info.IsLocked,
+		CreatedAt:   info.GroupCreated,
+	}
+	if withParticipants {
+		group.Participants = newGroupParticipantsResponse(info.Participants)
+	}
+	return group
+}
+
+// newGroupParticipantsResponse converts WhatsMeow group participants into their API representation.
+func newGroupParticipantsResponse(participants []types.GroupParticipant) []GroupParticipantResponse {
+	result := make([]GroupParticipantResponse, len(participants))
+	for i, participant := range participants {
+		result[i] = GroupParticipantResponse{
+			JID:          participant.JID.String(),
+			PhoneNumber:  participant.JID.User,
+			IsAdmin:      participant.IsAdmin,
+			IsSuperAdmin: participant.IsSuperAdmin,
+			Error:        participant.Error,
+		}
+	}
+	return result
+}
+
+// parseParticipantJIDs converts phone numbers or JIDs into user JIDs.
+func parseParticipantJIDs(participants []string) []types.JID {
+	jids := make([]types.JID, len(participants))
+	for i, participant := range participants {
+		jids[i] = parseUserJID(participant)
+	}
+	return jids
+}
+
+// groupError wraps an error returned by WhatsApp for a group operation.
+func groupError(operation string, err error) error {
+	return tracerr.Wrap(fmt.Errorf("%w: %s: %w", ErrGroupOperationFailure, operation, err))
+}
+
+// ListJoinedGroups returns the groups the device participates in, without their participants.
+func ListJoinedGroups(jid string) ([]GroupResponse, error) {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := client.GetJoinedGroups()
+	if err != nil {
+		return nil, groupError("list joined groups", err)
+	}
+
+	result := make([]GroupResponse, len(groups))
+	for i, group := range groups {
+		result[i] = newGroupResponse(group, false)
+	}
+	return result, nil
+}
+
+// GetGroupInfo returns the details and participants of a group.
+func GetGroupInfo(jid string, group types.JID) (*GroupResponse, error) {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := client.GetGroupInfo(group)
+	if err != nil {
+		return nil, groupError("get group info", err)
+	}
+
+	response := newGroupResponse(info, true)
+	return &response, nil
+}
+
+// CreateGroup creates a group with the given name and participants (phone numbers or JIDs).
+// Participants that could not be added are returned with their WhatsApp error code.
+func CreateGroup(jid string, name string, participants []string) (*GroupResponse, error) {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := client.CreateGroup(whatsmeow.ReqCreateGroup{
+		Name:         name,
+		Participants: parseParticipantJIDs(participants),
+	})
+	if err != nil {
+		return nil, groupError("create group", err)
+	}
+
+	response := newGroupResponse(info, true)
+	return &response, nil
+}
+
+// UpdateGroupParticipants adds, removes, promotes or demotes participants of a group.
+func UpdateGroupParticipants(jid string, group types.JID, action string, participants []string) ([]GroupParticipantResponse, error) {
+	change, ok := groupParticipantActions[action]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGroupAction, action)
+	}
+
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.UpdateGroupParticipants(group, parseParticipantJIDs(participants), change)
+	if err != nil {
+		return nil, groupError(action+" participants", err)
+	}
+	return newGroupParticipantsResponse(result), nil
+}
+
+// UpdateGroupInfo changes the name and/or description of a group. Nil values are left unchanged.
+func UpdateGroupInfo(jid string, group types.JID, name *string, description *string) error {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if name != nil {
+		if err := client.SetGroupName(group, *name); err != nil {
+			return groupError("set group name", err)
+		}
+	}
+	if description != nil {
+		if err := client.SetGroupDescription(group, *description); err != nil {
+			return groupError("set group description", err)
+		}
+	}
+	return nil
+}
+
+// SetGroupPicture converts the image to a square JPEG and sets it as the group picture.
+// It returns the ID of the new picture.
+func SetGroupPicture(jid string, group types.JID, image []byte) (string, error) {
+	picture, err := ConvertImageToProfilePicture(image)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMediaConversion, err)
+	}
+
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return "", err
+	}
+
+	pictureID, err := client.SetGroupPhoto(group, picture)
+	if err != nil {
+		return "", groupError("set group picture", err)
+	}
+	return pictureID, nil
+}
+
+// GetGroupInviteLink returns the invite link of a group, revoking the previous one when reset is true.
+func GetGroupInviteLink(jid string, group types.JID, reset bool) (string, error) {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return "", err
+	}
+
+	link, err := client.GetGroupInviteLink(group, reset)
+	if err != nil {
+		return "", groupError("get invite link", err)
+	}
+	return link, nil
+}
+
+// JoinGroupWithLink joins a group using an invite link (or just its code) and returns the group JID.
+func JoinGroupWithLink(jid string, link string) (types.JID, error) {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return types.JID{}, err
+	}
+
+	group, err := client.JoinGroupWithLink(link)
+	if err != nil {
+		return types.JID{}, groupError("join group", err)
+	}
+	return group, nil
+}
+
+// LeaveGroup makes the device leave a group.
+func LeaveGroup(jid string, group types.JID) error {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if err := client.LeaveGroup(group); err != nil {
+		return groupError("leave group", err)
+	}
+	return nil
+}
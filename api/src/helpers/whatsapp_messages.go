@@ -5,97 +5,409 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/jackc/pgx/v4/stdlib"
 	"github.com/ztrue/tracerr"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
 	"google.golang.org/protobuf/proto"
+
+	"whatsgoingon/data"
+	"whatsgoingon/handler"
+	"whatsgoingon/store"
 )
 
-// SendMessage sends a text message to a recipient using WhatsApp.
-// The function retrieves the WhatsApp client by JID, checks if the recipient number exists, and sends the message.
-func SendMessage(jid string, message string, recipient string) (*whatsmeow.SendResponse, error) {
+// messageBuilder builds the WhatsApp message to send to the destination using the connected client of the device.
+type messageBuilder func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error)
+
+// sendToRecipient retrieves the WhatsApp client by JID, resolves the recipient (phone number or JID),
+// builds the message, applies the send options (e.g. reply context) and sends it.
+// Nothing is sent once the context is cancelled, e.g. when the client disconnects while "typing...".
+func sendToRecipient(ctx context.Context, jid string, recipient string, opts SendOptions, build messageBuilder) (*whatsmeow.SendResponse, error) {
 	// Retrieve WhatsApp client for the given JID.
 	client, err := GetWhatsAppClientByJID(jid)
 	if err != nil {
 		return nil, err
 	}
-	defer client.Disconnect()
 
-	// Check if the recipient number exists and get its JID.
-	destination, err := CheckIfNumberExistsAndGetJID(recipient, client)
+	// Resolve the recipient JID, checking that numbers exist on WhatsApp and that groups are joined.
+	destination, err := ResolveRecipientJID(recipient, client)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create an encrypted WhatsApp message.
-	encryptedMessage := &waE2E.Message{
-		Conversation: proto.String(message),
+	// Build the message content, uploading any media it needs.
+	encryptedMessage, err := build(client, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach the reply context, if any.
+	applyContextInfo(encryptedMessage, buildContextInfo(client, jid, destination, opts))
+
+	// Show "typing..." first when requested, giving up if the request is cancelled meanwhile.
+	if err := simulateTyping(ctx, client, destination, opts.TypingDuration); err != nil {
+		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrMessageSending, err))
 	}
 
 	// Set a timeout for the context to avoid blocking.
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	sendCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Send the message and return the response or error.
-	resp, err := client.SendMessage(ctx, destination, encryptedMessage)
+	resp, err := client.SendMessage(sendCtx, destination, encryptedMessage)
 	if err != nil {
 		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrMessageSending, err))
 	}
 
+	// Store the sent message in the history and start tracking its delivery status.
+	if device, err := store.GetDeviceByJID(jid); err != nil {
+		handler.FailOnError(err, "Failed to retrieve device to record sent message")
+	} else {
+		saveSentMessage(device.ID, sentMessageEvent(client.Store.ID.ToNonAD(), client.Store.PushName, destination, resp, encryptedMessage))
+		if isStatusTracked(encryptedMessage) {
+			recordMessageSent(device.ID, resp.ID, destination, resp.Timestamp)
+		}
+	}
+
 	return &resp, nil
 }
 
-// SendSticker sends a sticker to a recipient on WhatsApp.
-// It retrieves the WhatsApp client, converts the sticker image to WebP, uploads it, and sends the sticker message.
-func SendSticker(jid string, stickerData []byte, recipient string) (*whatsmeow.SendResponse, error) {
-	// Retrieve WhatsApp client for the given JID.
-	client, err := GetWhatsAppClientByJID(jid)
-	if err != nil {
-		return nil, err
+// isStatusTracked reports whether the delivery status of a sent message is tracked.
+// Reactions, edits and revokes never get receipts of their own.
+func isStatusTracked(message *waE2E.Message) bool {
+	return message.GetReactionMessage() == nil && message.GetProtocolMessage() == nil && message.GetEditedMessage() == nil
+}
+
+// sentMessageEvent builds the message event of a message sent by the device, as WhatsApp would deliver it.
+// The message is unwrapped like received ones, so edits are seen as the protocol message they carry.
+func sentMessageEvent(ownJID types.JID, pushName string, destination types.JID, resp whatsmeow.SendResponse, message *waE2E.Message) *events.Message {
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:     destination,
+				Sender:   ownJID,
+				IsFromMe: true,
+				IsGroup:  destination.Server == types.GroupServer,
+			},
+			ID:        resp.ID,
+			PushName:  pushName,
+			Timestamp: resp.Timestamp,
+		},
+		RawMessage: message,
 	}
-	defer client.Disconnect()
+	return evt.UnwrapRaw()
+}
 
-	// Check if the recipient number exists and get its JID.
-	destination, err := CheckIfNumberExistsAndGetJID(recipient, client)
+// saveSentMessage stores a message sent through the API in the message history, logging any failure.
+func saveSentMessage(deviceID int, evt *events.Message) {
+	content, err := data.ConvertEventToStoredMessage(*evt, nil)
 	if err != nil {
-		return nil, err
+		handler.FailOnError(err, "Failed to convert sent message")
+		return
 	}
 
-	// Convert the sticker image to WebP format.
-	stickerDataWebp, err := ConvertImageToWebp(stickerData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert sticker to webp: %v", err)
+	if err := store.SaveMessage(deviceID, content); err != nil {
+		handler.FailOnError(err, "Failed to save sent message")
 	}
+}
 
-	// Upload the WebP sticker to WhatsApp servers.
-	stickerUpload, err := client.Upload(context.Background(), stickerDataWebp, whatsmeow.MediaImage)
-	if err != nil {
-		return nil, fmt.Errorf("failed to upload sticker: %v", err)
+// SendMessage sends a text message to a recipient using WhatsApp.
+// The function retrieves the WhatsApp client by JID, checks if the recipient number exists, and sends the message.
+func SendMessage(ctx context.Context, jid string, message string, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Attach the preview of the first link when requested; a failed preview still sends the text.
+		if opts.LinkPreview {
+			if linkURL := FindFirstURL(message); linkURL != "" {
+				preview, err := defaultLinkPreviewFetcher.Fetch(ctx, linkURL)
+				if err == nil {
+					return &waE2E.Message{ExtendedTextMessage: buildLinkPreviewMessage(message, preview)}, nil
+				}
+				handler.FailOnError(err, "Failed to fetch link preview")
+			}
+		}
+
+		// Create an encrypted WhatsApp message.
+		return &waE2E.Message{
+			Conversation: proto.String(message),
+		}, nil
+	})
+}
+
+// buildLinkPreviewMessage builds an extended text message carrying the preview of a link in the text.
+func buildLinkPreviewMessage(message string, preview *LinkPreview) *waE2E.ExtendedTextMessage {
+	extendedText := &waE2E.ExtendedTextMessage{
+		Text:         proto.String(message),
+		MatchedText:  proto.String(preview.MatchedText),
+		CanonicalURL: proto.String(preview.CanonicalURL),
+		Title:        proto.String(preview.Title),
+		Description:  proto.String(preview.Description),
+		PreviewType:  waE2E.ExtendedTextMessage_NONE.Enum(),
+	}
+	if len(preview.Thumbnail) > 0 {
+		extendedText.JPEGThumbnail = preview.Thumbnail
+		extendedText.ThumbnailWidth = proto.Uint32(uint32(preview.ThumbnailWidth))
+		extendedText.ThumbnailHeight = proto.Uint32(uint32(preview.ThumbnailHeight))
 	}
+	return extendedText
+}
 
-	// Create the sticker message with the upload details.
-	encryptedMessage := &waE2E.Message{
-		StickerMessage: &waE2E.StickerMessage{
-			URL:           proto.String(stickerUpload.URL),
-			DirectPath:    proto.String(stickerUpload.DirectPath),
-			MediaKey:      stickerUpload.MediaKey,
-			Mimetype:      proto.String("image/webp"),
-			FileSHA256:    stickerUpload.FileSHA256,
-			FileEncSHA256: stickerUpload.FileEncSHA256,
-			FileLength:    proto.Uint64(stickerUpload.FileLength),
-		},
+// SendSticker sends a sticker to a recipient on WhatsApp.
+// It retrieves the WhatsApp client, converts the sticker image to WebP, uploads it, and sends the sticker message.
+func SendSticker(ctx context.Context, jid string, stickerData []byte, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Convert the sticker image to WebP format.
+		stickerDataWebp, err := ConvertImageToWebp(stickerData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert sticker to webp: %v", err)
+		}
+
+		// Upload the WebP sticker to WhatsApp servers.
+		stickerUpload, err := client.Upload(context.Background(), stickerDataWebp, whatsmeow.MediaImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload sticker: %v", err)
+		}
+
+		// Create the sticker message with the upload details.
+		return &waE2E.Message{
+			StickerMessage: &waE2E.StickerMessage{
+				URL:           proto.String(stickerUpload.URL),
+				DirectPath:    proto.String(stickerUpload.DirectPath),
+				MediaKey:      stickerUpload.MediaKey,
+				Mimetype:      proto.String("image/webp"),
+				FileSHA256:    stickerUpload.FileSHA256,
+				FileEncSHA256: stickerUpload.FileEncSHA256,
+				FileLength:    proto.Uint64(stickerUpload.FileLength),
+			},
+		}, nil
+	})
+}
+
+// SendImage sends an image with an optional caption to a recipient on WhatsApp.
+// It generates a JPEG thumbnail, uploads the image and sends the image message.
+func SendImage(ctx context.Context, jid string, image *MediaFile, caption string, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Generate the preview thumbnail and read the image dimensions.
+		thumbnail, width, height, err := GenerateJPEGThumbnail(image.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate image thumbnail: %v", err)
+		}
+
+		// Upload the image to WhatsApp servers.
+		imageUpload, err := client.Upload(context.Background(), image.Data, whatsmeow.MediaImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload image: %v", err)
+		}
+
+		// Create the image message with the upload details.
+		imageMessage := &waE2E.ImageMessage{
+			URL:           proto.String(imageUpload.URL),
+			DirectPath:    proto.String(imageUpload.DirectPath),
+			MediaKey:      imageUpload.MediaKey,
+			Mimetype:      proto.String(image.MimeType),
+			FileSHA256:    imageUpload.FileSHA256,
+			FileEncSHA256: imageUpload.FileEncSHA256,
+			FileLength:    proto.Uint64(imageUpload.FileLength),
+			Width:         proto.Uint32(uint32(width)),
+			Height:        proto.Uint32(uint32(height)),
+			JPEGThumbnail: thumbnail,
+		}
+		if caption != "" {
+			imageMessage.Caption = proto.String(caption)
+		}
+
+		return &waE2E.Message{ImageMessage: imageMessage}, nil
+	})
+}
+
+// SendDocument sends a document (PDF, spreadsheet, etc.) with an optional caption to a recipient on WhatsApp.
+// The original file name and mime type are preserved so the recipient can open the file.
+func SendDocument(ctx context.Context, jid string, document *MediaFile, caption string, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Upload the document to WhatsApp servers.
+		documentUpload, err := client.Upload(context.Background(), document.Data, whatsmeow.MediaDocument)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload document: %v", err)
+		}
+
+		// Create the document message with the upload details.
+		fileName := MediaFileName(document, "document")
+		documentMessage := &waE2E.DocumentMessage{
+			URL:           proto.String(documentUpload.URL),
+			DirectPath:    proto.String(documentUpload.DirectPath),
+			MediaKey:      documentUpload.MediaKey,
+			Mimetype:      proto.String(document.MimeType),
+			FileSHA256:    documentUpload.FileSHA256,
+			FileEncSHA256: documentUpload.FileEncSHA256,
+			FileLength:    proto.Uint64(documentUpload.FileLength),
+			FileName:      proto.String(fileName),
+			Title:         proto.String(fileName),
+		}
+		if caption != "" {
+			documentMessage.Caption = proto.String(caption)
+		}
+
+		return &waE2E.Message{DocumentMessage: documentMessage}, nil
+	})
+}
+
+// SendAudio sends an audio file to a recipient on WhatsApp, either as a voice note (ptt) or as a regular audio file.
+// The audio is transcoded when needed and its duration and waveform are computed before uploading;
+// the conversion is abandoned when the context is cancelled.
+func SendAudio(ctx context.Context, jid string, audio *MediaFile, ptt bool, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Convert the audio and compute its duration and waveform.
+		converted, err := PrepareAudio(ctx, audio, ptt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert audio: %v", err)
+		}
+
+		// Upload the audio to WhatsApp servers.
+		audioUpload, err := client.Upload(ctx, converted.Data, whatsmeow.MediaAudio)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload audio: %v", err)
+		}
+
+		// Create the audio message with the upload details.
+		return &waE2E.Message{
+			AudioMessage: &waE2E.AudioMessage{
+				URL:           proto.String(audioUpload.URL),
+				DirectPath:    proto.String(audioUpload.DirectPath),
+				MediaKey:      audioUpload.MediaKey,
+				Mimetype:      proto.String(converted.MimeType),
+				FileSHA256:    audioUpload.FileSHA256,
+				FileEncSHA256: audioUpload.FileEncSHA256,
+				FileLength:    proto.Uint64(audioUpload.FileLength),
+				Seconds:       proto.Uint32(converted.Seconds),
+				Waveform:      converted.Waveform,
+				PTT:           proto.Bool(ptt),
+			},
+		}, nil
+	})
+}
+
+// SendVideo sends an MP4 video with an optional caption to a recipient on WhatsApp.
+// The video dimensions, duration and first-frame thumbnail are read before uploading.
+// When gifPlayback is true the video is shown as a looping GIF. Probing is abandoned when the context is cancelled.
+func SendVideo(ctx context.Context, jid string, video *MediaFile, caption string, gifPlayback bool, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	if len(video.Data) > MaxVideoSize {
+		return nil, ErrMediaTooLarge
 	}
 
-	// Set a timeout for the context to avoid blocking.
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Read the video metadata and generate the thumbnail.
+		info, err := ProbeVideo(ctx, video.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read video: %v", err)
+		}
 
-	// Send the sticker message and return the response or error.
-	resp, err := client.SendMessage(ctx, destination, encryptedMessage)
-	if err != nil {
-		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrMessageSending, err))
+		// Upload the video to WhatsApp servers.
+		videoUpload, err := client.Upload(ctx, video.Data, whatsmeow.MediaVideo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload video: %v", err)
+		}
+
+		// Create the video message with the upload details.
+		videoMessage := &waE2E.VideoMessage{
+			URL:           proto.String(videoUpload.URL),
+			DirectPath:    proto.String(videoUpload.DirectPath),
+			MediaKey:      videoUpload.MediaKey,
+			Mimetype:      proto.String(video.MimeType),
+			FileSHA256:    videoUpload.FileSHA256,
+			FileEncSHA256: videoUpload.FileEncSHA256,
+			FileLength:    proto.Uint64(videoUpload.FileLength),
+			Width:         proto.Uint32(info.Width),
+			Height:        proto.Uint32(info.Height),
+			Seconds:       proto.Uint32(info.Seconds),
+			JPEGThumbnail: info.Thumbnail,
+			GifPlayback:   proto.Bool(gifPlayback),
+		}
+		if caption != "" {
+			videoMessage.Caption = proto.String(caption)
+		}
+
+		return &waE2E.Message{VideoMessage: videoMessage}, nil
+	})
+}
+
+// SendReaction reacts to a message with an emoji on WhatsApp. An empty emoji removes a previous reaction.
+// The sender is the author of the reacted message; when empty it is resolved from the stored history.
+func SendReaction(ctx context.Context, jid string, recipient string, messageID string, sender string, emoji string) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, SendOptions{}, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		author := resolveMessageAuthor(client, destination, sender, findStoredMessage(jid, messageID))
+		return client.BuildReaction(destination, author, messageID, emoji), nil
+	})
+}
+
+// EditMessage replaces the text of a message previously sent by the device.
+// When the original message is in the stored history, it must be our own and within WhatsApp's edit window.
+func EditMessage(ctx context.Context, jid string, recipient string, messageID string, newText string) (*whatsmeow.SendResponse, error) {
+	if original := findStoredMessage(jid, messageID); original != nil {
+		if !original.IsFromMe {
+			return nil, ErrMessageNotFromMe
+		}
+		if time.Since(original.Timestamp) > whatsmeow.EditWindow {
+			return nil, fmt.Errorf("%w: edit window of %s has passed", ErrEditWindowExpired, whatsmeow.EditWindow)
+		}
 	}
 
-	return &resp, nil
+	return sendToRecipient(ctx, jid, recipient, SendOptions{}, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		return client.BuildEdit(destination, messageID, &waE2E.Message{
+			Conversation: proto.String(newText),
+		}), nil
+	})
+}
+
+// RevokeMessage deletes a message for everyone in the chat.
+// The sender is empty for our own messages, or the author's number/JID when revoking as a group admin.
+func RevokeMessage(ctx context.Context, jid string, recipient string, messageID string, sender string) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, SendOptions{}, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		author := types.EmptyJID
+		if sender != "" {
+			author = resolveMessageAuthor(client, destination, sender, nil)
+		}
+		return client.BuildRevoke(destination, author, messageID), nil
+	})
+}
+
+// SendLocation sends a location pin with an optional name and address to a recipient on WhatsApp.
+func SendLocation(ctx context.Context, jid string, location data.Location, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		locationMessage := &waE2E.LocationMessage{
+			DegreesLatitude:  proto.Float64(location.Latitude),
+			DegreesLongitude: proto.Float64(location.Longitude),
+		}
+		if location.Name != "" {
+			locationMessage.Name = proto.String(location.Name)
+		}
+		if location.Address != "" {
+			locationMessage.Address = proto.String(location.Address)
+		}
+
+		return &waE2E.Message{LocationMessage: locationMessage}, nil
+	})
+}
+
+// SendContacts sends one or more contact cards to a recipient on WhatsApp.
+// A single contact is sent as a contact message, several as a contacts array message.
+func SendContacts(ctx context.Context, jid string, contacts []data.Contact, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	return sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		contactMessages := make([]*waE2E.ContactMessage, len(contacts))
+		for i, contact := range contacts {
+			contactMessages[i] = &waE2E.ContactMessage{
+				DisplayName: proto.String(contact.Name),
+				Vcard:       proto.String(data.BuildVCard(contact)),
+			}
+		}
+
+		if len(contactMessages) == 1 {
+			return &waE2E.Message{ContactMessage: contactMessages[0]}, nil
+		}
+		return &waE2E.Message{
+			ContactsArrayMessage: &waE2E.ContactsArrayMessage{
+				DisplayName: proto.String(fmt.Sprintf("%d contacts", len(contactMessages))),
+				Contacts:    contactMessages,
+			},
+		}, nil
+	})
 }
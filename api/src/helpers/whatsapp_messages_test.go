@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"whatsgoingon/data"
+)
+
+var (
+	testOwnJID  = types.NewJID("5511900000000", types.DefaultUserServer)
+	testChatJID = types.NewJID("5511911111111", types.DefaultUserServer)
+)
+
+// convertSent converts a message sent by the device the way saveSentMessage does.
+func convertSent(t *testing.T, message *waE2E.Message) *data.StoredMessage {
+	t.Helper()
+
+	resp := whatsmeow.SendResponse{ID: "SENT-ID", Timestamp: time.Now()}
+	stored, err := data.ConvertEventToStoredMessage(*sentMessageEvent(testOwnJID, "Me", testChatJID, resp, message), nil)
+	if err != nil {
+		t.Fatalf("ConvertEventToStoredMessage: %v", err)
+	}
+	return stored
+}
+
+func TestSentEditIsStoredAsEditOfOriginal(t *testing.T) {
+	var client *whatsmeow.Client
+	edit := client.BuildEdit(testChatJID, "ORIGINAL-ID", &waE2E.Message{Conversation: proto.String("new text")})
+
+	stored := convertSent(t, edit)
+	if stored.EventType != data.EventTypeMessageEdit {
+		t.Fatalf("EventType = %q, want %q", stored.EventType, data.EventTypeMessageEdit)
+	}
+	if stored.TargetMessageID != "ORIGINAL-ID" {
+		t.Errorf("TargetMessageID = %q, want ORIGINAL-ID", stored.TargetMessageID)
+	}
+	if stored.Text != "new text" {
+		t.Errorf("Text = %q, want %q", stored.Text, "new text")
+	}
+	if !stored.IsFromMe || stored.ChatJID != testChatJID.String() {
+		t.Errorf("IsFromMe = %v, ChatJID = %q", stored.IsFromMe, stored.ChatJID)
+	}
+	if isStatusTracked(edit) {
+		t.Error("edits must not be status tracked")
+	}
+}
+
+func TestSentRevokeIsStoredAsRevokeOfOriginal(t *testing.T) {
+	revoke := &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+		Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+		Key:  &waCommon.MessageKey{RemoteJID: proto.String(testChatJID.String()), FromMe: proto.Bool(true), ID: proto.String("ORIGINAL-ID")},
+	}}
+
+	stored := convertSent(t, revoke)
+	if stored.EventType != data.EventTypeMessageRevoke {
+		t.Fatalf("EventType = %q, want %q", stored.EventType, data.EventTypeMessageRevoke)
+	}
+	if stored.TargetMessageID != "ORIGINAL-ID" {
+		t.Errorf("TargetMessageID = %q, want ORIGINAL-ID", stored.TargetMessageID)
+	}
+	if isStatusTracked(revoke) {
+		t.Error("revokes must not be status tracked")
+	}
+}
+
+func TestSentReactionAndTextStatusTracking(t *testing.T) {
+	reaction := &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{
+		Key:  &waCommon.MessageKey{ID: proto.String("ORIGINAL-ID")},
+		Text: proto.String("👍"),
+	}}
+	if stored := convertSent(t, reaction); stored.MediaType != "REACTION" || stored.TargetMessageID != "ORIGINAL-ID" {
+		t.Errorf("reaction stored as %q targeting %q", stored.MediaType, stored.TargetMessageID)
+	}
+	if isStatusTracked(reaction) {
+		t.Error("reactions must not be status tracked")
+	}
+
+	text := &waE2E.Message{Conversation: proto.String("hello")}
+	if stored := convertSent(t, text); stored.EventType != data.EventTypeMessage || stored.Text != "hello" {
+		t.Errorf("text stored as %q with text %q", stored.EventType, stored.Text)
+	}
+	if !isStatusTracked(text) {
+		t.Error("regular messages must be status tracked")
+	}
+}
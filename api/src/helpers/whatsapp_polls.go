@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ztrue/tracerr"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"whatsgoingon/data"
+	"whatsgoingon/handler"
+	"whatsgoingon/store"
+)
+
+// SendPoll sends a poll to a recipient on WhatsApp and stores it so incoming votes can be tallied.
+// A selectableCount of 0 lets voters select any number of options.
+func SendPoll(ctx context.Context, jid string, name string, options []string, selectableCount int, recipient string, opts SendOptions) (*whatsmeow.SendResponse, error) {
+	var pollMessage *waE2E.Message
+	var chat types.JID
+	var ownJID types.JID
+
+	resp, err := sendToRecipient(ctx, jid, recipient, opts, func(client *whatsmeow.Client, destination types.JID) (*waE2E.Message, error) {
+		// Build the poll; WhatsMeow generates the message secret and keeps it in its store on send.
+		pollMessage = client.BuildPollCreation(name, options, selectableCount)
+		chat = destination
+		ownJID = client.Store.ID.ToNonAD()
+		return pollMessage, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the poll options and secret to decrypt and tally the votes later.
+	device, err := store.GetDeviceByJID(jid)
+	if err != nil {
+		handler.FailOnError(err, "Failed to retrieve device to store sent poll")
+		return resp, nil
+	}
+	savePoll(device.ID, resp.ID, chat.String(), ownJID.String(), pollMessage)
+
+	return resp, nil
+}
+
+// SavePollFromEvent stores a poll received (or sent from another linked device) by the device.
+func SavePollFromEvent(deviceID int, msgEvent *events.Message) {
+	savePoll(deviceID, msgEvent.Info.ID, msgEvent.Info.Chat.String(), msgEvent.Info.Sender.ToNonAD().String(), msgEvent.Message)
+}
+
+// savePoll stores the poll carried by the message, logging any failure.
+func savePoll(deviceID int, messageID string, chatJID string, senderJID string, message *waE2E.Message) {
+	content := data.ConvertPollCreation(message)
+	if content == nil {
+		return
+	}
+
+	err := store.SavePoll(&data.Poll{
+		DeviceID:        deviceID,
+		MessageID:       messageID,
+		ChatJID:         chatJID,
+		SenderJID:       senderJID,
+		Name:            content.Name,
+		Options:         content.Options,
+		SelectableCount: content.SelectableCount,
+		MessageSecret:   message.GetMessageContextInfo().GetMessageSecret(),
+		CreatedAt:       time.Now(),
+	})
+	if err != nil {
+		handler.FailOnError(err, "Failed to store poll")
+	}
+}
+
+// ResolvePollVote decrypts a poll vote event, maps the selected option hashes back to their names
+// and stores it as the latest vote of the voter. The decrypted vote is set on the stored message.
+func ResolvePollVote(client *whatsmeow.Client, msgEvent *events.Message, deviceID int, content *data.StoredMessage) error {
+	poll, err := store.GetPollByMessageID(deviceID, content.TargetMessageID)
+	if err != nil {
+		return tracerr.Wrap(fmt.Errorf("%w: %v", ErrPollNotFound, err))
+	}
+
+	// Decrypt the vote, restoring the poll secret into the WhatsMeow store if it went missing.
+	vote, err := client.DecryptPollVote(msgEvent)
+	if errors.Is(err, whatsmeow.ErrOriginalMessageSecretNotFound) && len(poll.MessageSecret) > 0 {
+		chat, _ := types.ParseJID(poll.ChatJID)
+		sender, _ := types.ParseJID(poll.SenderJID)
+		if err = client.Store.MsgSecrets.PutMessageSecret(chat, sender, poll.MessageID, poll.MessageSecret); err == nil {
+			vote, err = client.DecryptPollVote(msgEvent)
+		}
+	}
+	if err != nil {
+		return tracerr.Wrap(fmt.Errorf("failed to decrypt poll vote: %v", err))
+	}
+
+	// Map the SHA-256 hashes of the selected options back to their names.
+	selectedOptions := make([]string, 0, len(vote.GetSelectedOptions()))
+	optionHashes := whatsmeow.HashPollOptions(poll.Options)
+	for _, selected := range vote.GetSelectedOptions() {
+		for i, hash := range optionHashes {
+			if bytes.Equal(selected, hash) {
+				selectedOptions = append(selectedOptions, poll.Options[i])
+				break
+			}
+		}
+	}
+
+	err = store.SavePollVote(&data.PollVote{
+		PollID:          poll.ID,
+		VoterJID:        content.SenderJID,
+		SelectedOptions: selectedOptions,
+		VotedAt:         content.Timestamp,
+	})
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+
+	content.PollVote = &data.PollVoteContent{
+		PollMessageID:   poll.MessageID,
+		PollName:        poll.Name,
+		SelectedOptions: selectedOptions,
+	}
+	return nil
+}
+
+// GetPollResults tallies the latest vote of every voter of a poll of the device.
+func GetPollResults(deviceID int, messageID string) (*data.PollResults, error) {
+	poll, err := store.GetPollByMessageID(deviceID, messageID)
+	if err != nil {
+		return nil, tracerr.Wrap(fmt.Errorf("%w: %v", ErrPollNotFound, err))
+	}
+
+	votes, err := store.GetPollVotes(poll.ID)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	results := &data.PollResults{
+		MessageID: poll.MessageID,
+		ChatJID:   poll.ChatJID,
+		Name:      poll.Name,
+		Options:   make([]data.PollOptionResult, len(poll.Options)),
+	}
+	optionIndex := make(map[string]int, len(poll.Options))
+	for i, option := range poll.Options {
+		results.Options[i] = data.PollOptionResult{Name: option, Voters: []string{}}
+		optionIndex[option] = i
+	}
+
+	// Count each selected option; voters who removed their vote have no selected options.
+	for _, vote := range votes {
+		if len(vote.SelectedOptions) > 0 {
+			results.TotalVoters++
+		}
+		for _, option := range vote.SelectedOptions {
+			if i, ok := optionIndex[option]; ok {
+				results.Options[i].Votes++
+				results.Options[i].Voters = append(results.Options[i].Voters, vote.VoterJID)
+			}
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,247 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ztrue/tracerr"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"whatsgoingon/data"
+	"whatsgoingon/handler"
+	"whatsgoingon/store"
+)
+
+const (
+	// MaxSimulatedTyping is the longest "typing..." indicator shown before sending a message.
+	MaxSimulatedTyping = 20 * time.Second
+)
+
+// Chat presence states accepted by the API.
+const (
+	ChatStateComposing = "composing" // "typing..."
+	ChatStateRecording = "recording" // "recording audio..."
+	ChatStatePaused    = "paused"    // Stops the typing or recording indicator
+)
+
+// chatPresence is the WhatsApp chat presence sent for a chat state of the API.
+type chatPresence struct {
+	state types.ChatPresence
+	media types.ChatPresenceMedia
+}
+
+// chatPresences maps the chat states accepted by the API to their WhatsApp chat presence.
+var chatPresences = map[string]chatPresence{
+	ChatStateComposing: {types.ChatPresenceComposing, types.ChatPresenceMediaText},
+	ChatStateRecording: {types.ChatPresenceComposing, types.ChatPresenceMediaAudio},
+	ChatStatePaused:    {types.ChatPresencePaused, types.ChatPresenceMediaText},
+}
+
+// Error definitions for presence issues.
+var (
+	ErrInvalidPresence  = errors.New("invalid presence state")
+	ErrPresenceFailure  = errors.New("failed to update presence")
+	ErrPresenceNotFound = errors.New("no presence received for the contact")
+	ErrSenderRequired   = errors.New("sender is required to mark group messages as read")
+)
+
+// MarkMessagesRead marks messages of a chat as read, showing the blue ticks to their sender.
+// In groups the sender, the author of the messages (phone number or JID), is required.
+func MarkMessagesRead(jid string, chat string, messageIDs []string, sender string) error {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return err
+	}
+
+	chatJID, err := ResolveRecipientJID(chat, client)
+	if err != nil {
+		return err
+	}
+
+	// In direct chats the messages are always sent by the chat itself; group receipts need the participant.
+	senderJID := chatJID
+	if sender != "" {
+		senderJID = parseUserJID(sender)
+	} else if chatJID.Server == types.GroupServer {
+		return fmt.Errorf("%w: %s", ErrSenderRequired, chatJID)
+	}
+
+	readAt := time.Now()
+	if err := client.MarkRead(messageIDs, readAt, chatJID, senderJID); err != nil {
+		return tracerr.Wrap(fmt.Errorf("%w: mark read: %v", ErrPresenceFailure, err))
+	}
+
+	// Keep the unread count of the chat in the message history up to date.
+	if device, err := store.GetDeviceByJID(jid); err != nil {
+		handler.FailOnError(err, "Failed to retrieve device to record read messages")
+	} else {
+		recordMessagesRead(device.ID, chatJID.String(), messageIDs, readAt)
+	}
+	return nil
+}
+
+// SendChatPresence shows or stops the "typing..." or "recording audio..." indicator in a chat.
+// An unknown state is rejected with ErrInvalidPresence before the device and the chat are resolved.
+func SendChatPresence(jid string, chat string, state string) error {
+	presence, ok := chatPresences[state]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidPresence, state)
+	}
+
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return err
+	}
+
+	chatJID, err := ResolveRecipientJID(chat, client)
+	if err != nil {
+		return err
+	}
+
+	return sendChatPresence(client, chatJID, presence)
+}
+
+// sendChatPresence sends the chat presence to the chat.
+func sendChatPresence(client *whatsmeow.Client, chat types.JID, presence chatPresence) error {
+	if err := client.SendChatPresence(chat, presence.state, presence.media); err != nil {
+		return tracerr.Wrap(fmt.Errorf("%w: chat presence: %v", ErrPresenceFailure, err))
+	}
+	return nil
+}
+
+// SetPresence sets the global availability of the device ("available" or "unavailable").
+// While unavailable, the device does not show as online and contacts receive no read receipts in real time.
+func SetPresence(jid string, presence string) error {
+	state := types.Presence(presence)
+	if state != types.PresenceAvailable && state != types.PresenceUnavailable {
+		return fmt.Errorf("%w: %s", ErrInvalidPresence, presence)
+	}
+
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if err := client.SendPresence(state); err != nil {
+		return tracerr.Wrap(fmt.Errorf("%w: %v", ErrPresenceFailure, err))
+	}
+	return nil
+}
+
+// simulateTyping shows "typing..." in the chat for the given duration (capped at MaxSimulatedTyping)
+// before a message is sent. Presence failures are logged and never block the message, but the wait
+// ends early with the context error when the context is cancelled, so the message is not sent.
+func simulateTyping(ctx context.Context, client *whatsmeow.Client, chat types.JID, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	if err := sendChatPresence(client, chat, chatPresences[ChatStateComposing]); err != nil {
+		handler.FailOnError(err, "Failed to send typing presence")
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(min(duration, MaxSimulatedTyping))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+
+	if err := sendChatPresence(client, chat, chatPresences[ChatStatePaused]); err != nil {
+		handler.FailOnError(err, "Failed to send paused presence")
+	}
+	return ctx.Err()
+}
+
+// SubscribeContactsPresence subscribes the device to the online/offline presence of the contacts
+// (phone numbers or JIDs) and remembers them so the subscriptions are renewed after reconnecting.
+// WhatsApp only delivers presences while the device itself is available.
+func SubscribeContactsPresence(jid string, deviceID int, contacts []string) ([]string, error) {
+	client, err := GetWhatsAppClientByJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribed := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		contactJID := parseUserJID(contact)
+		if err := client.SubscribePresence(contactJID); err != nil {
+			return subscribed, tracerr.Wrap(fmt.Errorf("%w: subscribe to %s: %v", ErrPresenceFailure, contactJID, err))
+		}
+		subscribed = append(subscribed, contactJID.String())
+	}
+
+	if err := AddPresenceSubscriptions(context.Background(), deviceID, subscribed); err != nil {
+		return subscribed, tracerr.Wrap(fmt.Errorf("%w: store subscriptions: %v", ErrPresenceFailure, err))
+	}
+	return subscribed, nil
+}
+
+// ResubscribePresences renews the presence subscriptions of the device, which WhatsApp drops on every reconnection.
+func ResubscribePresences(client *whatsmeow.Client, deviceID int) {
+	jids, err := GetPresenceSubscriptions(context.Background(), deviceID)
+	if err != nil {
+		handler.FailOnError(err, "Failed to retrieve presence subscriptions")
+		return
+	}
+
+	for _, jid := range jids {
+		contactJID, err := types.ParseJID(jid)
+		if err != nil {
+			continue
+		}
+		if err := client.SubscribePresence(contactJID); err != nil {
+			handler.FailOnError(err, fmt.Sprintf("Failed to resubscribe to presence of %s", jid))
+		}
+	}
+}
+
+// RecordPresence stores the presence of a contact in Redis. It returns the presence when a tracked contact
+// went online or offline, which is worth notifying, or nil otherwise.
+func RecordPresence(deviceID int, evt *events.Presence) *data.ContactPresence {
+	ctx := context.Background()
+
+	presence := data.ContactPresence{
+		EventType: data.EventTypePresence,
+		JID:       evt.From.ToNonAD().String(),
+		Online:    !evt.Unavailable,
+		UpdatedAt: time.Now(),
+	}
+	if !evt.LastSeen.IsZero() {
+		presence.LastSeen = &evt.LastSeen
+	}
+
+	previous, err := SavePresenceToRedis(ctx, deviceID, presence)
+	if err != nil {
+		handler.FailOnError(err, "Failed to store presence in Redis")
+		return nil
+	}
+	if previous != nil && previous.Online == presence.Online {
+		return nil
+	}
+
+	tracked, err := IsPresenceSubscribed(ctx, deviceID, presence.JID)
+	if err != nil || !tracked {
+		return nil
+	}
+	return &presence
+}
+
+// GetContactPresence returns the last known presence of a contact (phone number or JID).
+func GetContactPresence(deviceID int, contact string) (*data.ContactPresence, error) {
+	contactJID := parseUserJID(contact)
+
+	presence, err := GetPresenceFromRedis(context.Background(), deviceID, contactJID.String())
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if presence == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPresenceNotFound, contactJID)
+	}
+	return presence, nil
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	wmstore "go.mau.fi/whatsmeow/store"
+)
+
+func TestSendChatPresenceRejectsInvalidStateBeforeLookup(t *testing.T) {
+	// No device or database exists here, so reaching the lookup would fail with another error.
+	if err := SendChatPresence("5511900000000@s.whatsapp.net", "5511911111111", "typing"); !errors.Is(err, ErrInvalidPresence) {
+		t.Fatalf("SendChatPresence = %v, want ErrInvalidPresence", err)
+	}
+}
+
+func TestSimulateTypingStopsWhenCancelled(t *testing.T) {
+	client := &whatsmeow.Client{Store: &wmstore.Device{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, duration := range []time.Duration{0, MaxSimulatedTyping} {
+		if err := simulateTyping(ctx, client, testChatJID, duration); !errors.Is(err, context.Canceled) {
+			t.Errorf("simulateTyping for %s = %v, want context.Canceled", duration, err)
+		}
+	}
+	if err := simulateTyping(context.Background(), client, testChatJID, 0); err != nil {
+		t.Errorf("simulateTyping without typing = %v, want nil", err)
+	}
+}
@@ -1,21 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	_ "github.com/mattn/go-sqlite3"
-	"go.mau.fi/whatsmeow/store"
+	waStore "go.mau.fi/whatsmeow/store"
 	"google.golang.org/protobuf/proto"
 	"whatsgoingon/conf"
 	"whatsgoingon/events"
+	"whatsgoingon/helpers"
 	"whatsgoingon/routes"
+	"whatsgoingon/store"
 )
 
 func main() {
 	// Set the device properties for WhatsApp API usage.
-	store.DeviceProps.Os = proto.String("UatzAPI")
+	waStore.DeviceProps.Os = proto.String("UatzAPI")
 
 	// Load environment variables from .env file.
 	err := godotenv.Load(".env")
@@ -27,6 +35,31 @@ func main() {
 	// Initialize tokens for authentication or any necessary configuration.
 	conf.InitToken()
 
+	// Load the key signing the media download URLs (a random one is used, with a warning, if it is not set).
+	if err := conf.InitMediaTokenSecret(); err != nil {
+		log.Fatalf("Failed to load the media URL secret: %v", err)
+	}
+
+	// Open the PostgreSQL connection and the shared WhatsMeow store once for the whole application.
+	if err := store.InitConnections(); err != nil {
+		log.Fatalf("Failed to initialise database connections: %v", err)
+	}
+
+	// Make sure the search language exists, since every message insert is indexed with it.
+	if err := store.ValidateSearchLanguage(); err != nil {
+		log.Fatalf("Invalid SEARCH_LANGUAGE: %v", err)
+	}
+
+	// Check for ffmpeg and ffprobe, used to convert audio and video. Without them only audio and video sends are refused.
+	if err := helpers.CheckMediaTools(); err != nil {
+		log.Printf("[WARNING] Media tools unavailable, audio and video cannot be sent: %v", err)
+	}
+
+	// Open the media store where the media downloaded from messages is kept.
+	if err := store.InitMediaStore(); err != nil {
+		log.Fatalf("Failed to initialise the media store: %v", err)
+	}
+
 	// Initialize the Gin router.
 	r := gin.Default()
 
@@ -42,12 +75,53 @@ func main() {
 	r.GET("/device", routes.DeviceList)              // Get a list of devices
 	r.GET("/device/:deviceId", routes.GetDeviceInfo) // Get device information by device ID
 
+	// History Routes
+	r.GET("/device/:deviceId/chats", routes.ChatList)                   // List chats with their latest message and unread count
+	r.GET("/device/:deviceId/chats/:jid/messages", routes.ChatMessages) // Get the paginated message history of a chat
+	r.GET("/device/:deviceId/search", routes.SearchMessages)            // Full-text search over the stored messages
+
 	// Listener Routes
 	r.GET("/start_listener", routes.StartListener) // Start listener for messages
 
 	// Message Routes
-	r.POST("/send/message", routes.SendMessage) // Send a text message
-	r.POST("/send/sticker", routes.SendSticker) // Send a sticker
+	r.POST("/send/message", routes.SendMessage)   // Send a text message
+	r.POST("/send/sticker", routes.SendSticker)   // Send a sticker
+	r.POST("/send/image", routes.SendImage)       // Send an image with an optional caption
+	r.POST("/send/document", routes.SendDocument) // Send a document with its file name and mimetype
+	r.POST("/send/audio", routes.SendAudio)       // Send an audio file or voice note (ptt)
+	r.POST("/send/video", routes.SendVideo)       // Send an MP4 video with an optional caption
+	r.POST("/send/reaction", routes.SendReaction) // React to a message (empty emoji removes the reaction)
+	r.POST("/send/location", routes.SendLocation) // Send a location pin
+	r.POST("/send/contact", routes.SendContact)   // Send one or more contact cards (vCard)
+	r.POST("/send/poll", routes.SendPoll)         // Send a poll with up to 12 options
+
+	r.PATCH("/message/:id", routes.EditMessage)        // Edit the text of a sent message
+	r.DELETE("/message/:id", routes.RevokeMessage)     // Delete a message for everyone
+	r.GET("/message/:id/status", routes.MessageStatus) // Get the delivery status of a sent message
+
+	// Chat and Presence Routes
+	r.POST("/chat/read", routes.MarkRead)                   // Mark incoming messages of a chat as read
+	r.POST("/chat/presence", routes.ChatPresence)           // Show or stop the typing/recording indicator
+	r.POST("/presence", routes.SetPresence)                 // Set the device as available or unavailable
+	r.POST("/presence/subscribe", routes.SubscribePresence) // Track the online/offline presence of contacts
+	r.GET("/presence/:jid", routes.ContactPresence)         // Get the last known presence of a contact
+
+	// Group Routes
+	r.GET("/group", routes.GroupList)                            // List the groups the device participates in
+	r.POST("/group", routes.GroupCreate)                         // Create a group
+	r.POST("/group/join", routes.GroupJoin)                      // Join a group through an invite link
+	r.GET("/group/:jid", routes.GroupInfo)                       // Get group details and participants
+	r.PATCH("/group/:jid", routes.GroupUpdate)                   // Change the group name and/or description
+	r.DELETE("/group/:jid", routes.GroupLeave)                   // Leave a group
+	r.POST("/group/:jid/participants", routes.GroupParticipants) // Add, remove, promote or demote participants
+	r.PUT("/group/:jid/picture", routes.GroupPicture)            // Change the group picture
+	r.GET("/group/:jid/invite", routes.GroupInviteLink)          // Get (or reset) the group invite link
+
+	// Poll Routes
+	r.GET("/poll/:message_id/results", routes.PollResults) // Get the running tally of a poll
+
+	// Media Routes
+	r.GET(conf.MediaRoute, conf.MediaTokenMiddleware(), routes.MediaDownload) // Download a stored media (API key or signed URL)
 
 	// Webhook Routes
 	r.GET("/webhook", routes.WebhookList)                       // List all webhooks
@@ -56,9 +130,28 @@ func main() {
 	r.GET("/webhook/:deviceID", routes.WebhookByDevice)         // Get active webhook for a specific device
 	r.GET("/webhook/:deviceID/all", routes.WebhookListByDevice) // List all webhooks for a device
 
-	// Run the server on port 8080.
-	err = r.Run(":8080")
-	if err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Run the server on port 8080 until an interrupt or termination signal is received.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	// Give in-flight requests a few seconds to finish before closing the connections.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
 	}
+
+	// Disconnect every WhatsApp client before closing the stores they depend on.
+	helpers.GetClientManager().DisconnectAll()
+	store.CloseConnections()
 }
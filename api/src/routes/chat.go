@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"whatsgoingon/helpers"
+	"whatsgoingon/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MarkReadRequest represents the request payload for marking messages of a chat as read.
+type MarkReadRequest struct {
+	MessageRequest
+	MessageIDs []string `json:"message_ids"`      // IDs of the incoming messages to mark as read
+	Sender     string   `json:"sender,omitempty"` // Author of the messages, required in groups
+}
+
+// ChatPresenceRequest represents the request payload for showing a typing or recording indicator in a chat.
+type ChatPresenceRequest struct {
+	MessageRequest
+	State string `json:"state"` // composing, recording or paused
+}
+
+// PresenceRequest represents the request payload for setting the global availability of a device.
+type PresenceRequest struct {
+	DeviceID int    `json:"device_id"` // ID of the device
+	Presence string `json:"presence"`  // available or unavailable
+}
+
+// PresenceSubscribeRequest represents the request payload for subscribing to the presence of contacts.
+type PresenceSubscribeRequest struct {
+	DeviceID int      `json:"device_id"` // ID of the device
+	Contacts []string `json:"contacts"`  // Phone numbers or JIDs of the contacts to track
+}
+
+// presenceErrorStatus maps presence errors to the HTTP status returned to the caller.
+func presenceErrorStatus(err error) int {
+	if errors.Is(err, helpers.ErrInvalidPresence) || errors.Is(err, helpers.ErrSenderRequired) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, helpers.ErrPresenceNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// MarkRead handles the request to mark incoming messages of a chat as read.
+func MarkRead(c *gin.Context) {
+	var requestBody MarkReadRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(requestBody.MessageIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message_ids is required"})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	if err := helpers.MarkMessagesRead(jid, requestBody.Recipient(), requestBody.MessageIDs, requestBody.Sender); err != nil {
+		c.JSON(presenceErrorStatus(err), gin.H{"error": "Failed to mark messages as read", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "message_ids": requestBody.MessageIDs})
+}
+
+// ChatPresence handles the request to show or stop the typing or recording indicator in a chat.
+func ChatPresence(c *gin.Context) {
+	var requestBody ChatPresenceRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	if err := helpers.SendChatPresence(jid, requestBody.Recipient(), requestBody.State); err != nil {
+		c.JSON(presenceErrorStatus(err), gin.H{"error": "Failed to send chat presence", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "state": requestBody.State})
+}
+
+// SetPresence handles the request to set the global availability (online status) of a device.
+func SetPresence(c *gin.Context) {
+	var requestBody PresenceRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if requestBody.DeviceID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "device_id is required"})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	if err := helpers.SetPresence(jid, requestBody.Presence); err != nil {
+		c.JSON(presenceErrorStatus(err), gin.H{"error": "Failed to set presence", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "presence": requestBody.Presence})
+}
+
+// SubscribePresence handles the request to track the online/offline presence of contacts.
+// Changes are kept in Redis and sent through the webhook.
+func SubscribePresence(c *gin.Context) {
+	var requestBody PresenceSubscribeRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if requestBody.DeviceID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "device_id is required"})
+		return
+	}
+	if len(requestBody.Contacts) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "contacts is required"})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	subscribed, err := helpers.SubscribeContactsPresence(jid, requestBody.DeviceID, requestBody.Contacts)
+	if err != nil {
+		c.JSON(presenceErrorStatus(err), gin.H{"error": "Failed to subscribe to presence", "details": err.Error(), "subscribed": subscribed})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "subscribed": subscribed})
+}
+
+// ContactPresence returns the last known presence of a contact.
+// It requires the contact number or JID as a URL parameter and the device ID as the "device_id" query parameter.
+func ContactPresence(c *gin.Context) {
+	deviceID, err := strconv.Atoi(c.Query("device_id"))
+	if err != nil || deviceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device_id"})
+		return
+	}
+
+	presence, err := helpers.GetContactPresence(deviceID, c.Param("jid"))
+	if err != nil {
+		c.JSON(presenceErrorStatus(err), gin.H{"error": "Failed to retrieve presence", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, presence)
+}
@@ -78,7 +78,6 @@ func GetDeviceInfo(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	defer client.Disconnect() // Ensure client is disconnected at the end
 
 	// Get device information using the client
 	deviceInfo := helpers.GetClientInfo(deviceIDInt, client)
@@ -0,0 +1,328 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"whatsgoingon/helpers"
+	"whatsgoingon/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// CreateGroupRequest represents the request payload for creating a group.
+type CreateGroupRequest struct {
+	DeviceID     int      `json:"device_id"`    // ID of the device creating the group
+	Name         string   `json:"name"`         // Group name (up to 25 characters)
+	Participants []string `json:"participants"` // Phone numbers or JIDs of the initial participants
+}
+
+// GroupParticipantsRequest represents the request payload for changing the participants of a group.
+type GroupParticipantsRequest struct {
+	DeviceID     int      `json:"device_id"`    // ID of the device managing the group
+	Action       string   `json:"action"`       // add, remove, promote or demote
+	Participants []string `json:"participants"` // Phone numbers or JIDs of the participants
+}
+
+// UpdateGroupRequest represents the request payload for changing the name or description of a group.
+type UpdateGroupRequest struct {
+	DeviceID    int     `json:"device_id"`             // ID of the device managing the group
+	Name        *string `json:"name,omitempty"`        // New group name, if it should change
+	Description *string `json:"description,omitempty"` // New group description, if it should change
+}
+
+// GroupPictureRequest represents the request payload for changing the picture of a group.
+// The image can be uploaded as the "image" multipart file, or referenced by URL or base64 string.
+type GroupPictureRequest struct {
+	DeviceID int    `json:"device_id" form:"device_id"`     // ID of the device managing the group
+	URL      string `json:"url,omitempty" form:"url"`       // URL to download the image from
+	Base64   string `json:"base64,omitempty" form:"base64"` // Base64 (or data URI) encoded image
+}
+
+// JoinGroupRequest represents the request payload for joining a group through an invite link.
+type JoinGroupRequest struct {
+	DeviceID   int    `json:"device_id"`   // ID of the device joining the group
+	InviteLink string `json:"invite_link"` // Invite link (https://chat.whatsapp.com/...) or its code
+}
+
+// groupDeviceJID retrieves the JID of the device managing groups, writing the error response on failure.
+func groupDeviceJID(c *gin.Context, deviceID int) (string, bool) {
+	if deviceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "device_id is required"})
+		return "", false
+	}
+
+	jid, err := store.GetJIDByDeviceID(deviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return "", false
+	}
+	return jid, true
+}
+
+// queryDeviceJID reads the device ID from the "device_id" query parameter and retrieves its JID.
+func queryDeviceJID(c *gin.Context) (string, bool) {
+	deviceID, err := strconv.Atoi(c.Query("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device_id"})
+		return "", false
+	}
+	return groupDeviceJID(c, deviceID)
+}
+
+// groupParam parses the group JID from the ":jid" URL parameter, writing the error response on failure.
+func groupParam(c *gin.Context) (types.JID, bool) {
+	group, err := helpers.ParseGroupJID(c.Param("jid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return types.JID{}, false
+	}
+	return group, true
+}
+
+// groupErrorStatus maps group management errors to the HTTP status returned to the caller.
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, whatsmeow.ErrGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, whatsmeow.ErrNotInGroup):
+		return http.StatusForbidden
+	case errors.Is(err, helpers.ErrInvalidGroupAction),
+		errors.Is(err, helpers.ErrMediaConversion),
+		errors.Is(err, whatsmeow.ErrInviteLinkInvalid),
+		errors.Is(err, whatsmeow.ErrInviteLinkRevoked),
+		errors.Is(err, whatsmeow.ErrInvalidImageFormat):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// GroupList returns the groups the device participates in.
+// It requires the device ID as the "device_id" query parameter.
+func GroupList(c *gin.Context) {
+	jid, ok := queryDeviceJID(c)
+	if !ok {
+		return
+	}
+
+	groups, err := helpers.ListJoinedGroups(jid)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to list groups", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GroupInfo returns the details and participants of a group.
+// It requires the group JID as a URL parameter and the device ID as the "device_id" query parameter.
+func GroupInfo(c *gin.Context) {
+	group, ok := groupParam(c)
+	if !ok {
+		return
+	}
+	jid, ok := queryDeviceJID(c)
+	if !ok {
+		return
+	}
+
+	info, err := helpers.GetGroupInfo(jid, group)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to retrieve group info", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// GroupCreate handles the request to create a new group.
+func GroupCreate(c *gin.Context) {
+	var requestBody CreateGroupRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if requestBody.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	jid, ok := groupDeviceJID(c, requestBody.DeviceID)
+	if !ok {
+		return
+	}
+
+	info, err := helpers.CreateGroup(jid, requestBody.Name, requestBody.Participants)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to create group", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, info)
+}
+
+// GroupParticipants handles the request to add, remove, promote or demote participants of a group.
+// Participants that could not be changed are returned with the error code given by WhatsApp.
+func GroupParticipants(c *gin.Context) {
+	group, ok := groupParam(c)
+	if !ok {
+		return
+	}
+
+	var requestBody GroupParticipantsRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if len(requestBody.Participants) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "participants is required"})
+		return
+	}
+
+	jid, ok := groupDeviceJID(c, requestBody.DeviceID)
+	if !ok {
+		return
+	}
+
+	participants, err := helpers.UpdateGroupParticipants(jid, group, requestBody.Action, requestBody.Participants)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to update group participants", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, participants)
+}
+
+// GroupUpdate handles the request to change the name and/or description of a group.
+func GroupUpdate(c *gin.Context) {
+	group, ok := groupParam(c)
+	if !ok {
+		return
+	}
+
+	var requestBody UpdateGroupRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if requestBody.Name == nil && requestBody.Description == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name or description is required"})
+		return
+	}
+
+	jid, ok := groupDeviceJID(c, requestBody.DeviceID)
+	if !ok {
+		return
+	}
+
+	if err := helpers.UpdateGroupInfo(jid, group, requestBody.Name, requestBody.Description); err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to update group", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "jid": group.String()})
+}
+
+// GroupPicture handles the request to change the picture of a group.
+func GroupPicture(c *gin.Context) {
+	group, ok := groupParam(c)
+	if !ok {
+		return
+	}
+
+	var requestBody GroupPictureRequest
+	if err := bindMediaRequest(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	jid, ok := groupDeviceJID(c, requestBody.DeviceID)
+	if !ok {
+		return
+	}
+
+	// Load the image from the multipart file, the URL or the base64 payload
+	image, err := loadMediaFile(c, "image", MediaRequest{URL: requestBody.URL, Base64: requestBody.Base64})
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": "Failed to load image", "details": err.Error()})
+		return
+	}
+
+	pictureID, err := helpers.SetGroupPicture(jid, group, image.Data)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to set group picture", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "jid": group.String(), "picture_id": pictureID})
+}
+
+// GroupInviteLink returns the invite link of a group.
+// It requires the device ID as the "device_id" query parameter; "reset=true" revokes the previous link.
+func GroupInviteLink(c *gin.Context) {
+	group, ok := groupParam(c)
+	if !ok {
+		return
+	}
+	jid, ok := queryDeviceJID(c)
+	if !ok {
+		return
+	}
+	reset, _ := strconv.ParseBool(c.Query("reset"))
+
+	link, err := helpers.GetGroupInviteLink(jid, group, reset)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to retrieve invite link", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jid": group.String(), "invite_link": link})
+}
+
+// GroupJoin handles the request to join a group through an invite link.
+func GroupJoin(c *gin.Context) {
+	var requestBody JoinGroupRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if requestBody.InviteLink == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invite_link is required"})
+		return
+	}
+
+	jid, ok := groupDeviceJID(c, requestBody.DeviceID)
+	if !ok {
+		return
+	}
+
+	group, err := helpers.JoinGroupWithLink(jid, requestBody.InviteLink)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to join group", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "jid": group.String()})
+}
+
+// GroupLeave handles the request to leave a group.
+// It requires the group JID as a URL parameter and the device ID as the "device_id" query parameter.
+func GroupLeave(c *gin.Context) {
+	group, ok := groupParam(c)
+	if !ok {
+		return
+	}
+	jid, ok := queryDeviceJID(c)
+	if !ok {
+		return
+	}
+
+	if err := helpers.LeaveGroup(jid, group); err != nil {
+		c.JSON(groupErrorStatus(err), gin.H{"error": "Failed to leave group", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Ok", "jid": group.String()})
+}
@@ -0,0 +1,186 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"whatsgoingon/data"
+	"whatsgoingon/helpers"
+	"whatsgoingon/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// History formats accepted by the "format" query parameter.
+const (
+	historyFormatFull         = "full"         // Full StoredMessage of each message
+	historyFormatConversation = "conversation" // Compact side/message/mime_type of each message
+)
+
+// historyDeviceID parses the device ID of the URL and checks that the device exists.
+// It writes the error response and returns false when the device is invalid.
+func historyDeviceID(c *gin.Context) (int, bool) {
+	deviceID, err := strconv.Atoi(c.Param("deviceId"))
+	if err != nil || deviceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device_id"})
+		return 0, false
+	}
+
+	if _, err := store.GetDeviceByID(deviceID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found", "details": err.Error()})
+		return 0, false
+	}
+	return deviceID, true
+}
+
+// ChatList returns the chats of a device with their latest message and number of unread messages.
+func ChatList(c *gin.Context) {
+	deviceID, ok := historyDeviceID(c)
+	if !ok {
+		return
+	}
+
+	chats, err := helpers.ListChats(deviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chats", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, chats)
+}
+
+// ChatMessages returns the message history of a chat, newest messages first, paginated with a cursor.
+// Optional query parameters: limit, cursor (next_cursor of the previous page), media_type,
+// from_me (true for sent, false for received) and format (full or conversation).
+func ChatMessages(c *gin.Context) {
+	deviceID, ok := historyDeviceID(c)
+	if !ok {
+		return
+	}
+
+	filter := data.MessageFilter{MediaType: strings.ToUpper(c.Query("media_type"))}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		filter.Limit = value
+	}
+
+	if fromMe := c.Query("from_me"); fromMe != "" {
+		value, err := strconv.ParseBool(fromMe)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from_me"})
+			return
+		}
+		filter.IsFromMe = &value
+	}
+
+	format := c.DefaultQuery("format", historyFormatFull)
+	if format != historyFormatFull && format != historyFormatConversation {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be full or conversation"})
+		return
+	}
+
+	page, err := helpers.GetChatMessages(deviceID, c.Param("jid"), c.Query("cursor"), filter)
+	if err != nil {
+		if errors.Is(err, helpers.ErrInvalidHistoryQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history query", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages", "details": err.Error()})
+		return
+	}
+
+	// Convert the messages to the requested format
+	var messages interface{}
+	if format == historyFormatConversation {
+		conversation := make([]store.Conversation, 0, len(page.Messages))
+		for _, message := range page.Messages {
+			conversation = append(conversation, store.NewConversation(message))
+		}
+		messages = conversation
+	} else {
+		stored := make([]*data.StoredMessage, 0, len(page.Messages))
+		for i := range page.Messages {
+			stored = append(stored, page.Messages[i].ToStoredMessage())
+		}
+		messages = stored
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages, "next_cursor": page.NextCursor})
+}
+
+// parseSearchTime parses a date (2006-01-02) or RFC 3339 timestamp of the search filters.
+// A date given as the upper bound includes the whole day.
+func parseSearchTime(value string, upperBound bool) (*time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if upperBound {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return &parsed, nil
+}
+
+// SearchMessages runs a full-text search over the text and captions of the messages of a device.
+// Required query parameter: q. Optional: chat, sender, media_type, from and to (date or RFC 3339),
+// limit and offset. Matches are highlighted with <b></b> in the snippet of each result.
+func SearchMessages(c *gin.Context) {
+	deviceID, ok := historyDeviceID(c)
+	if !ok {
+		return
+	}
+
+	search := data.MessageSearch{
+		Query:     c.Query("q"),
+		MediaType: strings.ToUpper(c.Query("media_type")),
+	}
+
+	for name, target := range map[string]*int{"limit": &search.Limit, "offset": &search.Offset} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"from": &search.Since, "to": &search.Until} {
+		if value := c.Query(name); value != "" {
+			parsed, err := parseSearchTime(value, name == "to")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name, "details": err.Error()})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	page, err := helpers.SearchMessages(deviceID, c.Query("chat"), c.Query("sender"), search)
+	if err != nil {
+		if errors.Is(err, helpers.ErrInvalidHistoryQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search query", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search messages", "details": err.Error()})
+		return
+	}
+
+	response := gin.H{"results": page.Results}
+	if page.NextOffset > 0 {
+		response["next_offset"] = page.NextOffset
+	}
+	c.JSON(http.StatusOK, response)
+}
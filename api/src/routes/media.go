@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"whatsgoingon/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MediaRequest represents the request payload for sending media messages.
+// The media can be uploaded as a multipart file, or referenced by URL or base64 string in a JSON body.
+type MediaRequest struct {
+	MessageRequest
+	Caption  string `json:"caption,omitempty" form:"caption"`     // Optional caption shown below the media
+	URL      string `json:"url,omitempty" form:"url"`             // URL to download the media from
+	Base64   string `json:"base64,omitempty" form:"base64"`       // Base64 (or data URI) encoded media
+	FileName string `json:"file_name,omitempty" form:"file_name"` // Optional file name override
+}
+
+// AudioRequest represents the request payload for sending audio messages.
+type AudioRequest struct {
+	MediaRequest
+	PTT bool `json:"ptt" form:"ptt"` // Send as a voice note (push-to-talk) instead of an audio file
+}
+
+// VideoRequest represents the request payload for sending video messages.
+type VideoRequest struct {
+	MediaRequest
+	GifPlayback bool `json:"gif_playback" form:"gif_playback"` // Play the video as a looping GIF
+}
+
+// bindMediaRequest binds a media request from either a JSON body or multipart/query form values.
+func bindMediaRequest(c *gin.Context, requestBody any) error {
+	if strings.HasPrefix(c.ContentType(), binding.MIMEJSON) {
+		return c.ShouldBindJSON(requestBody)
+	}
+	return c.ShouldBindWith(requestBody, binding.Form)
+}
+
+// loadMediaFile reads the media of the request from the multipart field, the URL or the base64 payload,
+// in that order of precedence.
+func loadMediaFile(c *gin.Context, field string, requestBody MediaRequest) (*helpers.MediaFile, error) {
+	var media *helpers.MediaFile
+	var err error
+
+	switch {
+	case requestBody.URL != "":
+		media, err = helpers.DownloadMedia(requestBody.URL)
+	case requestBody.Base64 != "":
+		media, err = helpers.DecodeBase64Media(requestBody.Base64)
+	default:
+		media, err = readMultipartMedia(c, field)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if requestBody.FileName != "" {
+		media.FileName = requestBody.FileName
+	}
+	return media, nil
+}
+
+// readMultipartMedia reads an uploaded file from the given multipart form field.
+func readMultipartMedia(c *gin.Context, field string) (*helpers.MediaFile, error) {
+	fileHeader, err := c.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("%s file, url or base64 is required: %v", field, err)
+	}
+	if fileHeader.Size > helpers.MaxMediaDownloadSize {
+		return nil, helpers.ErrMediaTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s file: %v", field, err)
+	}
+	defer func(file multipart.File) {
+		if err := file.Close(); err != nil {
+			fmt.Printf("Error closing %s file: %v\n", field, err)
+		}
+	}(file)
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %v", field, err)
+	}
+
+	return &helpers.MediaFile{
+		Data:     content,
+		FileName: fileHeader.Filename,
+		MimeType: helpers.DetectMimeType(content, fileHeader.Header.Get("Content-Type")),
+	}, nil
+}
+
+// mediaErrorStatus maps media loading errors to the HTTP status returned to the caller.
+func mediaErrorStatus(err error) int {
+	if errors.Is(err, helpers.ErrMediaTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
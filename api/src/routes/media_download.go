@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"whatsgoingon/helpers"
+	"whatsgoingon/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaDownload serves the content of a stored media with its Content-Type, Content-Length and ETag.
+// Range and conditional (If-None-Match, If-Modified-Since) requests are supported.
+// It is reachable with the API key or with the signed URL sent in webhooks and Redis.
+func MediaDownload(c *gin.Context) {
+	mediaID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || mediaID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return
+	}
+
+	media, content, err := helpers.OpenMedia(c.Request.Context(), mediaID)
+	if err != nil {
+		if errors.Is(err, store.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open media", "details": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	// The content never changes, so its digest is a strong ETag and it can be cached.
+	contentType := media.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("ETag", `"`+media.SHA256+`"`)
+	c.Header("Cache-Control", "private, max-age=86400, immutable")
+
+	http.ServeContent(c.Writer, c.Request, "", media.CreatedAt, content)
+}
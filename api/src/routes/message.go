@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"whatsgoingon/helpers"
+	"whatsgoingon/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeRequest represents the request payload for deleting a message for everyone.
+type RevokeRequest struct {
+	MessageRequest
+	Sender string `json:"sender,omitempty" form:"sender"` // Author of the message when revoking someone else's message as group admin
+}
+
+// EditMessage handles the request to edit the text of a message sent by the device.
+// The message ID comes from the URL and the new text from the "message" field of the body.
+func EditMessage(c *gin.Context) {
+	var requestBody MessageRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (the new text is required)
+	if err := requestBody.Validate(true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the edit using the helper function
+	resp, err := helpers.EditMessage(c.Request.Context(), jid, requestBody.Recipient(), c.Param("id"), requestBody.Message)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, helpers.ErrEditWindowExpired) || errors.Is(err, helpers.ErrMessageNotFromMe) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": "Failed to edit message", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody, resp))
+}
+
+// RevokeMessage handles the request to delete a message for everyone.
+// The device and recipient can be given in a JSON body or as query parameters.
+func RevokeMessage(c *gin.Context) {
+	var requestBody RevokeRequest
+	if err := c.ShouldBind(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the revoke using the helper function
+	resp, err := helpers.RevokeMessage(c.Request.Context(), jid, requestBody.Recipient(), c.Param("id"), requestBody.Sender)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke message", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// MessageStatus returns the delivery status (sent, delivered, read, played) of a message sent by the device.
+// It requires the message ID as a URL parameter and the device ID as the "device_id" query parameter.
+func MessageStatus(c *gin.Context) {
+	deviceID, err := strconv.Atoi(c.Query("device_id"))
+	if err != nil || deviceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device_id"})
+		return
+	}
+
+	status, err := helpers.GetMessageStatus(deviceID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, helpers.ErrMessageStatusNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message status not found", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve message status", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"whatsgoingon/helpers"
+	"whatsgoingon/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// minPollOptions and maxPollOptions are the limits WhatsApp applies to the number of poll options.
+	minPollOptions = 2
+	maxPollOptions = 12
+)
+
+// PollRequest represents the request payload for sending a poll.
+type PollRequest struct {
+	MessageRequest
+	Name            string   `json:"name"`             // The poll question
+	Options         []string `json:"options"`          // Names of the options (2 to 12, unique)
+	SelectableCount int      `json:"selectable_count"` // Maximum number of options a voter can select (0 means unlimited)
+}
+
+// Validate checks that the poll has a question and a valid set of options.
+func (p PollRequest) Validate() error {
+	if err := p.MessageRequest.Validate(false); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(p.Options) < minPollOptions || len(p.Options) > maxPollOptions {
+		return errors.New("options must have between 2 and 12 items")
+	}
+	seen := make(map[string]bool, len(p.Options))
+	for _, option := range p.Options {
+		if option == "" || seen[option] {
+			return errors.New("options must be non-empty and unique")
+		}
+		seen[option] = true
+	}
+	if p.SelectableCount < 0 || p.SelectableCount > len(p.Options) {
+		return errors.New("selectable_count must be between 0 and the number of options")
+	}
+	return nil
+}
+
+// SendPoll handles the request to send a poll.
+func SendPoll(c *gin.Context) {
+	var requestBody PollRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload
+	if err := requestBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the poll using the helper function
+	resp, err := helpers.SendPoll(c.Request.Context(), jid, requestBody.Name, requestBody.Options, requestBody.SelectableCount, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send poll", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// PollResults returns the running tally of a poll sent or received by a device.
+// It requires the poll message ID as a URL parameter and the device ID as the "device_id" query parameter.
+func PollResults(c *gin.Context) {
+	deviceID, err := strconv.Atoi(c.Query("device_id"))
+	if err != nil || deviceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device_id"})
+		return
+	}
+
+	// Tally the votes of the poll
+	results, err := helpers.GetPollResults(deviceID, c.Param("message_id"))
+	if err != nil {
+		if errors.Is(err, helpers.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Poll not found", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve poll results", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
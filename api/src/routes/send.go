@@ -7,18 +7,27 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"whatsgoingon/data"
 	"whatsgoingon/helpers"
 	"whatsgoingon/store"
 
 	"github.com/gin-gonic/gin"
+	"go.mau.fi/whatsmeow"
 )
 
 // MessageRequest represents the request payload for sending messages and stickers.
 type MessageRequest struct {
-	DeviceID        int    `json:"device_id"`         // ID of the device sending the message
-	RecipientNumber string `json:"recipient_number"`  // WhatsApp recipient number
-	Message         string `json:"message,omitempty"` // Message text (optional for stickers)
+	DeviceID        int      `json:"device_id" form:"device_id"`                             // ID of the device sending the message
+	RecipientNumber string   `json:"recipient_number" form:"recipient_number"`               // WhatsApp recipient number
+	RecipientJID    string   `json:"recipient_jid,omitempty" form:"recipient_jid"`           // Full recipient JID (group, newsletter or user), instead of a number
+	Message         string   `json:"message,omitempty" form:"message"`                       // Message text (optional for stickers)
+	QuotedMessageID string   `json:"quoted_message_id,omitempty" form:"quoted_message_id"`   // ID of the message to reply to
+	QuotedSender    string   `json:"quoted_sender,omitempty" form:"quoted_sender"`           // Author of the quoted message (number or JID)
+	Mentions        []string `json:"mentions,omitempty" form:"mentions"`                     // Numbers (or JIDs) mentioned with "@number" in the text
+	LinkPreview     bool     `json:"link_preview,omitempty" form:"link_preview"`             // Attach the preview of the first link in the text
+	SimulateTyping  int      `json:"simulate_typing_ms,omitempty" form:"simulate_typing_ms"` // Show "typing..." for this many milliseconds before sending
 }
 
 // Validate checks if the required fields in MessageRequest are provided.
@@ -27,22 +36,57 @@ func (m MessageRequest) Validate(checkMessage ...bool) error {
 	if m.DeviceID == 0 {
 		return errors.New("device_id is required")
 	}
-	if m.RecipientNumber == "" {
-		return errors.New("recipient_number is required")
+	if m.RecipientNumber == "" && m.RecipientJID == "" {
+		return errors.New("recipient_number or recipient_jid is required")
 	}
 	if m.Message == "" && len(checkMessage) > 0 && checkMessage[0] {
 		return errors.New("message is required")
 	}
+	if m.SimulateTyping < 0 || time.Duration(m.SimulateTyping)*time.Millisecond > helpers.MaxSimulatedTyping {
+		return fmt.Errorf("simulate_typing_ms must be between 0 and %d", helpers.MaxSimulatedTyping.Milliseconds())
+	}
 	return nil
 }
 
+// Recipient returns the recipient of the message: the JID when given, otherwise the phone number.
+func (m MessageRequest) Recipient() string {
+	if m.RecipientJID != "" {
+		return m.RecipientJID
+	}
+	return m.RecipientNumber
+}
+
+// SendOptions returns the optional send settings (reply context, mentions, link preview and typing simulation) requested by the caller.
+func (m MessageRequest) SendOptions() helpers.SendOptions {
+	return helpers.SendOptions{
+		QuotedMessageID: m.QuotedMessageID,
+		QuotedSender:    m.QuotedSender,
+		Mentions:        m.Mentions,
+		LinkPreview:     m.LinkPreview,
+		TypingDuration:  time.Duration(m.SimulateTyping) * time.Millisecond,
+	}
+}
+
 // MessageResponse represents the response structure after a message or sticker is sent.
 type MessageResponse struct {
-	Status          string    `json:"status"`           // Status of the operation
-	Timestamp       time.Time `json:"timestamp"`        // Timestamp of the message or sticker sent
-	ID              string    `json:"id"`               // WhatsApp message ID
-	DeviceID        int       `json:"device_id"`        // ID of the device used to send the message
-	RecipientNumber string    `json:"recipient_number"` // WhatsApp recipient number
+	Status          string    `json:"status"`                  // Status of the operation
+	Timestamp       time.Time `json:"timestamp"`               // Timestamp of the message or sticker sent
+	ID              string    `json:"id"`                      // WhatsApp message ID
+	DeviceID        int       `json:"device_id"`               // ID of the device used to send the message
+	RecipientNumber string    `json:"recipient_number"`        // WhatsApp recipient number
+	RecipientJID    string    `json:"recipient_jid,omitempty"` // Recipient JID, when the message was sent to a JID
+}
+
+// newMessageResponse builds the response returned after a message has been sent successfully.
+func newMessageResponse(requestBody MessageRequest, resp *whatsmeow.SendResponse) MessageResponse {
+	return MessageResponse{
+		Status:          "Ok",
+		Timestamp:       resp.Timestamp,
+		ID:              resp.ID,
+		DeviceID:        requestBody.DeviceID,
+		RecipientNumber: requestBody.RecipientNumber,
+		RecipientJID:    requestBody.RecipientJID,
+	}
 }
 
 // SendMessage handles the request to send a text message.
@@ -68,21 +112,14 @@ func SendMessage(c *gin.Context) {
 	}
 
 	// Send the message using the helper function
-	resp, err := helpers.SendMessage(jid, requestBody.Message, requestBody.RecipientNumber)
+	resp, err := helpers.SendMessage(c.Request.Context(), jid, requestBody.Message, requestBody.Recipient(), requestBody.SendOptions())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message", "details": err.Error()})
 		return
 	}
 
 	// Create and return the response
-	response := MessageResponse{
-		Status:          "Ok",
-		Timestamp:       resp.Timestamp,
-		ID:              resp.ID,
-		DeviceID:        requestBody.DeviceID,
-		RecipientNumber: requestBody.RecipientNumber,
-	}
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, newMessageResponse(requestBody, resp))
 }
 
 // SendSticker handles the request to send a sticker.
@@ -97,6 +134,9 @@ func SendSticker(c *gin.Context) {
 	requestBody := MessageRequest{
 		DeviceID:        dvcID,
 		RecipientNumber: c.Query("recipient_number"),
+		RecipientJID:    c.Query("recipient_jid"),
+		QuotedMessageID: c.Query("quoted_message_id"),
+		QuotedSender:    c.Query("quoted_sender"),
 	}
 
 	// Validate the request payload (without requiring message)
@@ -140,19 +180,351 @@ func SendSticker(c *gin.Context) {
 	}
 
 	// Send the sticker using the helper function
-	resp, err := helpers.SendSticker(jid, stickerData, requestBody.RecipientNumber)
+	resp, err := helpers.SendSticker(c.Request.Context(), jid, stickerData, requestBody.Recipient(), requestBody.SendOptions())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send sticker", "details": err.Error()})
 		return
 	}
 
 	// Create and return the response
-	response := MessageResponse{
-		Status:          "Ok",
-		Timestamp:       resp.Timestamp,
-		ID:              resp.ID,
-		DeviceID:        requestBody.DeviceID,
-		RecipientNumber: requestBody.RecipientNumber,
+	c.JSON(http.StatusOK, newMessageResponse(requestBody, resp))
+}
+
+// SendImage handles the request to send an image with an optional caption.
+// The image can be a multipart file in the "image" field, or a URL or base64 string.
+func SendImage(c *gin.Context) {
+	var requestBody MediaRequest
+	if err := bindMediaRequest(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Load the image from the upload, URL or base64 payload
+	image, err := loadMediaFile(c, "image", requestBody)
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": "Failed to load image", "details": err.Error()})
+		return
+	}
+	if !strings.HasPrefix(image.MimeType, "image/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is not an image", "details": image.MimeType})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the image using the helper function
+	resp, err := helpers.SendImage(c.Request.Context(), jid, image, requestBody.Caption, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send image", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// SendDocument handles the request to send a document with an optional caption.
+// The document can be a multipart file in the "document" field, or a URL or base64 string.
+func SendDocument(c *gin.Context) {
+	var requestBody MediaRequest
+	if err := bindMediaRequest(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Load the document from the upload, URL or base64 payload
+	document, err := loadMediaFile(c, "document", requestBody)
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": "Failed to load document", "details": err.Error()})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
 	}
-	c.JSON(http.StatusOK, response)
+
+	// Send the document using the helper function
+	resp, err := helpers.SendDocument(c.Request.Context(), jid, document, requestBody.Caption, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send document", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// SendAudio handles the request to send an audio file, as a voice note when "ptt" is true.
+// The audio can be a multipart file in the "audio" field, or a URL or base64 string.
+func SendAudio(c *gin.Context) {
+	var requestBody AudioRequest
+	if err := bindMediaRequest(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audio and video are converted with ffmpeg, which may not be installed
+	if err := helpers.RequireMediaTools(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Media conversion is unavailable", "details": err.Error()})
+		return
+	}
+
+	// Load the audio from the upload, URL or base64 payload
+	audio, err := loadMediaFile(c, "audio", requestBody.MediaRequest)
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": "Failed to load audio", "details": err.Error()})
+		return
+	}
+	if !strings.HasPrefix(audio.MimeType, "audio/") && !strings.HasPrefix(audio.MimeType, "video/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is not an audio", "details": audio.MimeType})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the audio using the helper function
+	resp, err := helpers.SendAudio(c.Request.Context(), jid, audio, requestBody.PTT, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send audio", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// SendVideo handles the request to send an MP4 video with an optional caption.
+// The video can be a multipart file in the "video" field, or a URL or base64 string.
+// Videos larger than WhatsApp's limit are rejected with 413 Request Entity Too Large.
+func SendVideo(c *gin.Context) {
+	var requestBody VideoRequest
+	if err := bindMediaRequest(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Audio and video are converted with ffmpeg, which may not be installed
+	if err := helpers.RequireMediaTools(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Media conversion is unavailable", "details": err.Error()})
+		return
+	}
+
+	// Load the video from the upload, URL or base64 payload
+	video, err := loadMediaFile(c, "video", requestBody.MediaRequest)
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": "Failed to load video", "details": err.Error()})
+		return
+	}
+	if video.MimeType != "video/mp4" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Video must be an MP4 file", "details": video.MimeType})
+		return
+	}
+	if len(video.Data) > helpers.MaxVideoSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "Video is too large",
+			"details": fmt.Sprintf("video has %d bytes, WhatsApp accepts up to %d bytes", len(video.Data), helpers.MaxVideoSize),
+		})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the video using the helper function
+	resp, err := helpers.SendVideo(c.Request.Context(), jid, video, requestBody.Caption, requestBody.GifPlayback, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send video", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// ReactionRequest represents the request payload for reacting to a message.
+type ReactionRequest struct {
+	MessageRequest
+	MessageID string `json:"message_id"`       // ID of the message to react to
+	Sender    string `json:"sender,omitempty"` // Author of the reacted message (number or JID), resolved from history when empty
+	Emoji     string `json:"emoji"`            // Reaction emoji; empty to remove a previous reaction
+}
+
+// SendReaction handles the request to react to a message, or to remove a reaction when the emoji is empty.
+func SendReaction(c *gin.Context) {
+	var requestBody ReactionRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if requestBody.MessageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message_id is required"})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the reaction using the helper function
+	resp, err := helpers.SendReaction(c.Request.Context(), jid, requestBody.Recipient(), requestBody.MessageID, requestBody.Sender, requestBody.Emoji)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reaction", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// LocationRequest represents the request payload for sending a location.
+type LocationRequest struct {
+	MessageRequest
+	Latitude  *float64 `json:"latitude"`          // Latitude in decimal degrees (-90 to 90)
+	Longitude *float64 `json:"longitude"`         // Longitude in decimal degrees (-180 to 180)
+	Name      string   `json:"name,omitempty"`    // Optional name of the place
+	Address   string   `json:"address,omitempty"` // Optional address of the place
+}
+
+// SendLocation handles the request to send a location pin.
+func SendLocation(c *gin.Context) {
+	var requestBody LocationRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if requestBody.Latitude == nil || *requestBody.Latitude < -90 || *requestBody.Latitude > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "latitude is required and must be between -90 and 90"})
+		return
+	}
+	if requestBody.Longitude == nil || *requestBody.Longitude < -180 || *requestBody.Longitude > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "longitude is required and must be between -180 and 180"})
+		return
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the location using the helper function
+	location := data.Location{
+		Latitude:  *requestBody.Latitude,
+		Longitude: *requestBody.Longitude,
+		Name:      requestBody.Name,
+		Address:   requestBody.Address,
+	}
+	resp, err := helpers.SendLocation(c.Request.Context(), jid, location, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send location", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
+}
+
+// ContactRequest represents the request payload for sending contact cards.
+type ContactRequest struct {
+	MessageRequest
+	Contacts []data.Contact `json:"contacts"` // Contacts to share; at least one with name and phone
+}
+
+// SendContact handles the request to send one or more contact cards (vCards).
+func SendContact(c *gin.Context) {
+	var requestBody ContactRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Validate the request payload (without requiring message)
+	if err := requestBody.Validate(false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(requestBody.Contacts) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "contacts is required"})
+		return
+	}
+	for i, contact := range requestBody.Contacts {
+		if contact.Name == "" || len(contact.Phones) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("contacts[%d] requires a name and at least one phone", i)})
+			return
+		}
+	}
+
+	// Retrieve the JID (WhatsApp ID) based on the device ID
+	jid, err := store.GetJIDByDeviceID(requestBody.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve device JID", "details": err.Error()})
+		return
+	}
+
+	// Send the contacts using the helper function
+	resp, err := helpers.SendContacts(c.Request.Context(), jid, requestBody.Contacts, requestBody.Recipient(), requestBody.SendOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send contact", "details": err.Error()})
+		return
+	}
+
+	// Create and return the response
+	c.JSON(http.StatusOK, newMessageResponse(requestBody.MessageRequest, resp))
 }
@@ -1,29 +1,30 @@
 package store
 
-import (
-	"fmt"
-	"whatsgoingon/data"
+import "whatsgoingon/data"
 
-	"github.com/ztrue/tracerr"
-	"go.mau.fi/whatsmeow"
-	"go.mau.fi/whatsmeow/types/events"
+// Sides of a conversation, telling who sent each message.
+const (
+	ConversationSideMe      = "me"      // The message was sent by the device
+	ConversationSideContact = "contact" // The message was sent by the contact (or a group participant)
 )
 
+// Conversation is the compact representation of a message in the history of a chat.
 type Conversation struct {
 	Side     string `json:"side"`
 	Message  string `json:"message"`
 	MimeType string `json:"mime_type"`
 }
 
-// SaveMessage stores a message in the database
-func SaveMessage(msg events.Message, client *whatsmeow.Client) (error, *data.StoredMessage) {
-	content, err := data.ConvertEventToStoredMessage(msg, client)
-	if err != nil {
-		tracerr.Print(err)
-		return fmt.Errorf("error getting message content: %v", err), nil
+// NewConversation converts a message of the history into its compact conversation representation.
+func NewConversation(message data.Message) Conversation {
+	side := ConversationSideContact
+	if message.IsFromMe {
+		side = ConversationSideMe
 	}
 
-	// Save the message to the database
-
-	return nil, content
+	return Conversation{
+		Side:     side,
+		Message:  message.Text,
+		MimeType: message.ContentMimeType,
+	}
 }
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"whatsgoingon/data"
+)
+
+// SaveMedia stores a media record, or returns the record already stored for the same content.
+func SaveMedia(media *data.Media) (data.Media, error) {
+	db := GetBunConnection()
+	ctx := context.Background()
+
+	_, err := db.NewInsert().
+		Model(media).
+		On("CONFLICT (sha256) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return data.Media{}, fmt.Errorf("failed to save media %s: %v", media.SHA256, err)
+	}
+
+	// Read the record back, as it may have been stored by a previous message
+	return GetMediaBySHA256(media.SHA256)
+}
+
+// GetMediaBySHA256 retrieves a media record by the SHA-256 digest of its content.
+func GetMediaBySHA256(digest string) (data.Media, error) {
+	db := GetBunConnection()
+
+	media := new(data.Media)
+	err := db.NewSelect().
+		Model(media).
+		Where("sha256 = ?", digest).
+		Scan(context.Background())
+
+	if err != nil {
+		return data.Media{}, fmt.Errorf("failed to retrieve media %s: %v", digest, err)
+	}
+	return *media, nil
+}
+
+// GetMediaByID retrieves a media record by its ID.
+func GetMediaByID(mediaID int64) (data.Media, error) {
+	db := GetBunConnection()
+
+	media := new(data.Media)
+	err := db.NewSelect().
+		Model(media).
+		Where("id = ?", mediaID).
+		Scan(context.Background())
+
+	if err != nil {
+		return data.Media{}, fmt.Errorf("failed to retrieve media ID %d: %v", mediaID, err)
+	}
+	return *media, nil
+}
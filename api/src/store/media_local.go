@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalMediaStore keeps media contents as files under a directory of the local filesystem.
+type LocalMediaStore struct {
+	root string // Directory holding the media files
+}
+
+// NewLocalMediaStore returns a media store writing under the root directory, creating it if needed.
+func NewLocalMediaStore(root string) (*LocalMediaStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("%w: create media directory %s: %v", ErrInvalidMediaStore, root, err)
+	}
+	return &LocalMediaStore{root: root}, nil
+}
+
+// path returns the file path of the key, refusing keys that would escape the root directory.
+func (s *LocalMediaStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: invalid media key %q", ErrMediaStoreOperation, key)
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
+
+// Put writes the content to the file of the key. The content is written to a temporary file first
+// and then renamed, so readers never see a partially written file.
+func (s *LocalMediaStore) Put(_ context.Context, key string, content []byte, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("%w: %v", ErrMediaStoreOperation, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMediaStoreOperation, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%w: write %s: %v", ErrMediaStoreOperation, key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%w: write %s: %v", ErrMediaStoreOperation, key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("%w: write %s: %v", ErrMediaStoreOperation, key, err)
+	}
+	return nil
+}
+
+// Open opens the file of the key for reading.
+func (s *LocalMediaStore) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrMediaNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: open %s: %v", ErrMediaStoreOperation, key, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLocalMediaStorePutOpen(t *testing.T) {
+	localStore, err := NewLocalMediaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalMediaStore: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := localStore.Put(ctx, "ab/content.txt", []byte("local content"), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	reader, err := localStore.Open(ctx, "ab/content.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil || string(content) != "local content" {
+		t.Fatalf("ReadAll = %q, %v", content, err)
+	}
+
+	if _, err := localStore.Open(ctx, "ab/missing.txt"); !errors.Is(err, ErrMediaNotFound) {
+		t.Fatalf("Open of a missing key = %v, want ErrMediaNotFound", err)
+	}
+}
+
+func TestLocalMediaStoreRejectsEscapingKeys(t *testing.T) {
+	localStore, err := NewLocalMediaStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalMediaStore: %v", err)
+	}
+
+	for _, key := range []string{"", "../outside", "/etc/passwd", "ab/../../outside"} {
+		if err := localStore.Put(context.Background(), key, []byte("x"), ""); !errors.Is(err, ErrMediaStoreOperation) {
+			t.Errorf("Put(%q) = %v, want ErrMediaStoreOperation", key, err)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3Algorithm is the signing algorithm of AWS Signature Version 4.
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	// s3UnsignedPayload is used as payload hash for requests whose body is not signed.
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// S3MediaStore keeps media contents as objects of an S3-compatible bucket (AWS S3, MinIO, ...).
+// Requests are signed with AWS Signature Version 4.
+type S3MediaStore struct {
+	Endpoint        *url.URL     // Base URL of the S3 service, e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Region          string       // Region of the bucket
+	Bucket          string       // Name of the bucket
+	AccessKeyID     string       // Access key used to sign the requests
+	SecretAccessKey string       // Secret key used to sign the requests
+	PathStyle       bool         // Address the bucket in the path (endpoint/bucket/key) instead of the host name
+	Client          *http.Client // HTTP client used for the requests
+}
+
+// NewS3MediaStoreFromEnv returns an S3 media store configured by the S3_ENDPOINT, S3_REGION, S3_BUCKET,
+// S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY and S3_PATH_STYLE environment variables.
+// Without S3_ENDPOINT the AWS endpoint of the region is used; a custom endpoint (such as MinIO)
+// uses path-style addressing unless S3_PATH_STYLE says otherwise.
+func NewS3MediaStoreFromEnv() (*S3MediaStore, error) {
+	region := getEnvDefault("S3_REGION", "us-east-1")
+	endpoint := os.Getenv("S3_ENDPOINT")
+	customEndpoint := endpoint != ""
+	if !customEndpoint {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	parsedEndpoint, err := url.Parse(endpoint)
+	if err != nil || parsedEndpoint.Scheme == "" || parsedEndpoint.Host == "" {
+		return nil, fmt.Errorf("%w: invalid S3_ENDPOINT %q", ErrInvalidMediaStore, endpoint)
+	}
+
+	s3Store := &S3MediaStore{
+		Endpoint:        parsedEndpoint,
+		Region:          region,
+		Bucket:          os.Getenv("S3_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		PathStyle:       getEnvBool("S3_PATH_STYLE", customEndpoint),
+		Client:          &http.Client{Timeout: 5 * time.Minute},
+	}
+	if s3Store.Bucket == "" || s3Store.AccessKeyID == "" || s3Store.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%w: S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required", ErrInvalidMediaStore)
+	}
+	return s3Store, nil
+}
+
+// objectURL returns the URL of the object stored under the key.
+func (s *S3MediaStore) objectURL(key string) *url.URL {
+	objectURL := *s.Endpoint
+	basePath := strings.TrimSuffix(objectURL.Path, "/")
+	if s.PathStyle {
+		objectURL.Path = basePath + "/" + s.Bucket + "/" + key
+	} else {
+		objectURL.Host = s.Bucket + "." + objectURL.Host
+		objectURL.Path = basePath + "/" + key
+	}
+	objectURL.RawPath = s3EscapePath(objectURL.Path)
+	return &objectURL
+}
+
+// Put uploads the content as the object of the key.
+func (s *S3MediaStore) Put(ctx context.Context, key string, content []byte, mimeType string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMediaStoreOperation, err)
+	}
+	if mimeType != "" {
+		request.Header.Set("Content-Type", mimeType)
+	}
+
+	payloadHash := sha256.Sum256(content)
+	response, err := s.do(request, hex.EncodeToString(payloadHash[:]))
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// Open returns a reader over the object of the key. The object is fetched lazily with range requests,
+// so seeking (for example to serve a range of the content) does not download the skipped bytes.
+func (s *S3MediaStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaStoreOperation, err)
+	}
+
+	response, err := s.do(request, s3UnsignedPayload)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	return &s3ObjectReader{ctx: ctx, store: s, key: key, size: response.ContentLength}, nil
+}
+
+// SignedURL returns a presigned URL downloading the object of the key directly from the bucket.
+func (s *S3MediaStore) SignedURL(key string, expiresIn time.Duration) (string, error) {
+	if expiresIn > MaxMediaURLExpiration {
+		expiresIn = MaxMediaURLExpiration
+	}
+
+	now := time.Now().UTC()
+	objectURL := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.AccessKeyID+"/"+s.credentialScope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiresIn.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	objectURL.RawQuery = s3CanonicalQuery(query)
+
+	headers := http.Header{}
+	headers.Set("Host", objectURL.Host)
+	signature := s.signature(now, http.MethodGet, objectURL, headers, []string{"host"}, s3UnsignedPayload)
+
+	objectURL.RawQuery += "&X-Amz-Signature=" + signature
+	return objectURL.String(), nil
+}
+
+// do signs and sends the request, turning S3 error responses into errors.
+func (s *S3MediaStore) do(request *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(request, payloadHash)
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMediaStoreOperation, err)
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		response.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrMediaNotFound, request.URL.Path)
+	case response.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		return nil, fmt.Errorf("%w: %s %s: %s: %s", ErrMediaStoreOperation, request.Method, request.URL.Path, response.Status, body)
+	}
+	return response, nil
+}
+
+// sign adds the AWS Signature Version 4 authorization headers to the request.
+func (s *S3MediaStore) sign(request *http.Request, payloadHash string) {
+	now := time.Now().UTC()
+	request.Header.Set("Host", request.URL.Host)
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Every header set so far takes part in the signature.
+	signedHeaders := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		signedHeaders = append(signedHeaders, strings.ToLower(name))
+	}
+	sort.Strings(signedHeaders)
+
+	signature := s.signature(now, request.Method, request.URL, request.Header, signedHeaders, payloadHash)
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKeyID, s.credentialScope(now), strings.Join(signedHeaders, ";"), signature))
+	request.Header.Del("Host") // Sent by net/http from the URL
+}
+
+// signature computes the AWS Signature Version 4 of a request.
+func (s *S3MediaStore) signature(now time.Time, method string, requestURL *url.URL, headers http.Header, signedHeaders []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers.Get(name)) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		requestURL.EscapedPath(),
+		s3CanonicalQuery(requestURL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format("20060102T150405Z"),
+		s.credentialScope(now),
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	signingKey := s3HMAC([]byte("AWS4"+s.SecretAccessKey), now.Format("20060102"))
+	signingKey = s3HMAC(signingKey, s.Region)
+	signingKey = s3HMAC(signingKey, "s3")
+	signingKey = s3HMAC(signingKey, "aws4_request")
+	return hex.EncodeToString(s3HMAC(signingKey, stringToSign))
+}
+
+// credentialScope returns the scope (date/region/service) the signing key is valid for.
+func (s *S3MediaStore) credentialScope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.Region + "/s3/aws4_request"
+}
+
+// s3HMAC returns the HMAC-SHA256 of the data with the key.
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape percent-encodes a value as required by Signature Version 4 (RFC 3986 unreserved characters are kept).
+func s3Escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// s3EscapePath percent-encodes every segment of a path, keeping the slashes.
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery encodes the query parameters sorted by name, as required by Signature Version 4.
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(name)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3ObjectReader reads an S3 object with range requests, reopening the download after every seek.
+type s3ObjectReader struct {
+	ctx    context.Context
+	store  *S3MediaStore
+	key    string
+	size   int64         // Size of the object
+	offset int64         // Position of the next read
+	body   io.ReadCloser // Open download starting at offset, nil until the next read
+}
+
+// Read reads from the current position, starting a range download if none is open.
+func (r *s3ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.body == nil {
+		request, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.store.objectURL(r.key).String(), nil)
+		if err != nil {
+			return 0, err
+		}
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+
+		response, err := r.store.do(request, s3UnsignedPayload)
+		if err != nil {
+			return 0, err
+		}
+		r.body = response.Body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+// Seek moves the position of the next read, closing the open download if the position changes.
+func (r *s3ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = r.offset + offset
+	case io.SeekEnd:
+		position = r.size + offset
+	default:
+		return 0, fmt.Errorf("%w: invalid whence %d", ErrMediaStoreOperation, whence)
+	}
+	if position < 0 {
+		return 0, fmt.Errorf("%w: negative position", ErrMediaStoreOperation)
+	}
+
+	if position != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = position
+	return position, nil
+}
+
+// Close closes the open download, if any.
+func (r *s3ObjectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Media store backends selected with the MEDIA_STORE environment variable.
+const (
+	MediaStoreLocal = "local" // Files in a directory of the local filesystem (default)
+	MediaStoreS3    = "s3"    // Objects in an S3-compatible bucket (AWS S3, MinIO, ...)
+)
+
+const (
+	// DefaultMediaPath is the directory used by the local media store when MEDIA_LOCAL_PATH is not set.
+	DefaultMediaPath = "./media"
+	// DefaultMediaURLExpiration is how long signed media URLs stay valid when MEDIA_URL_EXPIRATION is not set.
+	DefaultMediaURLExpiration = 24 * time.Hour
+	// MaxMediaURLExpiration is the longest validity of signed media URLs (the limit of S3 presigned URLs).
+	MaxMediaURLExpiration = 7 * 24 * time.Hour
+)
+
+// Error definitions for media store issues.
+var (
+	ErrMediaNotFound       = errors.New("media not found in the media store")
+	ErrInvalidMediaStore   = errors.New("invalid media store configuration")
+	ErrMediaStoreOperation = errors.New("media store operation failed")
+)
+
+// MediaStore keeps the content of the media downloaded from messages.
+// Contents are addressed by a key chosen by the caller and are immutable once written.
+type MediaStore interface {
+	// Put writes the content under the key, replacing any previous content.
+	Put(ctx context.Context, key string, content []byte, mimeType string) error
+	// Open returns a reader over the content stored under the key, or ErrMediaNotFound.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+}
+
+// MediaURLSigner is implemented by media stores that can hand out signed download URLs themselves.
+type MediaURLSigner interface {
+	// SignedURL returns a URL that downloads the content stored under the key until it expires.
+	SignedURL(key string, expiresIn time.Duration) (string, error)
+}
+
+var mediaStoreInstance MediaStore
+
+// InitMediaStore creates the media store configured by the environment at application startup.
+// MEDIA_STORE selects the backend: "local" (MEDIA_LOCAL_PATH) or "s3" (S3_* variables).
+func InitMediaStore() error {
+	var err error
+	switch backend := os.Getenv("MEDIA_STORE"); backend {
+	case "", MediaStoreLocal:
+		mediaStoreInstance, err = NewLocalMediaStore(getEnvDefault("MEDIA_LOCAL_PATH", DefaultMediaPath))
+	case MediaStoreS3:
+		mediaStoreInstance, err = NewS3MediaStoreFromEnv()
+	default:
+		err = fmt.Errorf("%w: unknown MEDIA_STORE %q", ErrInvalidMediaStore, backend)
+	}
+	return err
+}
+
+// GetMediaStore returns the media store created by InitMediaStore.
+func GetMediaStore() MediaStore {
+	return mediaStoreInstance
+}
+
+// MediaURLExpiration returns how long signed media URLs stay valid, read from MEDIA_URL_EXPIRATION
+// (a Go duration such as "1h" or "30m") and limited to MaxMediaURLExpiration.
+func MediaURLExpiration() time.Duration {
+	expiration, err := time.ParseDuration(os.Getenv("MEDIA_URL_EXPIRATION"))
+	if err != nil || expiration <= 0 {
+		return DefaultMediaURLExpiration
+	}
+	return min(expiration, MaxMediaURLExpiration)
+}
+
+// getEnvDefault returns the value of the environment variable, or the fallback when it is not set.
+func getEnvDefault(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvBool returns the boolean value of the environment variable, or the fallback when it is not set or invalid.
+func getEnvBool(name string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,279 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+	"whatsgoingon/data"
+
+	"github.com/uptrace/bun"
+)
+
+// SaveMessage stores a message received or sent by the device. A message already stored for the same
+// device and chat is ignored. Edits and revokes are not stored themselves but applied to the message they refer to.
+func SaveMessage(deviceID int, stored *data.StoredMessage) error {
+	db := GetBunConnection()
+	ctx := context.Background()
+
+	if stored.EventType != data.EventTypeMessageEdit && stored.EventType != data.EventTypeMessageRevoke {
+		message := data.NewMessage(deviceID, stored)
+		message.SearchLanguage = SearchLanguage()
+		_, err := db.NewInsert().
+			Model(message).
+			On("CONFLICT (device_id, chat_jid, message_id) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to save message %s for device ID %d: %v", stored.MessageID, deviceID, err)
+		}
+	}
+
+	// Reflect edits and revokes on the original message
+	update := db.NewUpdate().
+		Model((*data.Message)(nil)).
+		Where("device_id = ? AND chat_jid = ? AND message_id = ?", deviceID, stored.ChatJID, stored.TargetMessageID)
+
+	switch stored.EventType {
+	case data.EventTypeMessageEdit:
+		update = update.Set("text = ?", stored.Text).Set("edited_at = ?", stored.Timestamp)
+	case data.EventTypeMessageRevoke:
+		update = update.Set("revoked_at = ?", stored.Timestamp)
+	default:
+		return nil
+	}
+
+	if _, err := update.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to apply %s to message %s: %v", stored.EventType, stored.TargetMessageID, err)
+	}
+	return nil
+}
+
+// GetMessageByID retrieves the latest message of the device with the given WhatsApp ID.
+func GetMessageByID(deviceID int, messageID string) (data.Message, error) {
+	db := GetBunConnection()
+
+	message := new(data.Message)
+	err := db.NewSelect().
+		Model(message).
+		Where("device_id = ? AND message_id = ?", deviceID, messageID).
+		Order("timestamp DESC").
+		Limit(1).
+		Scan(context.Background())
+
+	if err != nil {
+		return data.Message{}, fmt.Errorf("failed to retrieve message %s for device ID %d: %v", messageID, deviceID, err)
+	}
+	return *message, nil
+}
+
+// DefaultSearchLanguage is the text search configuration used when SEARCH_LANGUAGE is not set.
+const DefaultSearchLanguage = "portuguese"
+
+// ErrInvalidSearchLanguage is returned when SEARCH_LANGUAGE is not a text search configuration of the database.
+var ErrInvalidSearchLanguage = errors.New("invalid search language")
+
+// searchHeadlineOptions controls the snippets returned by the full-text search.
+const searchHeadlineOptions = "StartSel=<b>, StopSel=</b>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// SearchLanguage returns the text search configuration (e.g. portuguese, english, simple) used to
+// index and search message texts, read from the SEARCH_LANGUAGE environment variable.
+func SearchLanguage() string {
+	if language := os.Getenv("SEARCH_LANGUAGE"); language != "" {
+		return language
+	}
+	return DefaultSearchLanguage
+}
+
+// ValidateSearchLanguage checks at application startup that the search language is a text search
+// configuration of the database. An unknown configuration would make every message insert fail.
+func ValidateSearchLanguage() error {
+	db := GetBunConnection()
+	language := SearchLanguage()
+
+	exists, err := db.NewSelect().
+		Table("pg_catalog.pg_ts_config").
+		Where("cfgname = ?", language).
+		Exists(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to look up search language %q: %v", language, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %q is not a text search configuration of the database", ErrInvalidSearchLanguage, language)
+	}
+	return nil
+}
+
+// chatMessageCondition restricts the history to the messages shown in a chat, leaving out
+// edits, revokes, votes and reactions, which only change other messages.
+const chatMessageCondition = "msg.event_type = 'MESSAGE' AND msg.media_type NOT IN ('REACTION', 'UNKNOWN')"
+
+// GetChats retrieves the chats of the device with their latest message and number of unread messages,
+// most recent chats first.
+func GetChats(deviceID int) ([]data.Chat, error) {
+	db := GetBunConnection()
+	ctx := context.Background()
+
+	// Latest message of each chat
+	var lastMessages []data.Message
+	err := db.NewSelect().
+		Model(&lastMessages).
+		DistinctOn("msg.chat_jid").
+		Where("msg.device_id = ?", deviceID).
+		Where(chatMessageCondition).
+		OrderExpr("msg.chat_jid, msg.timestamp DESC, msg.id DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve chats for device ID %d: %v", deviceID, err)
+	}
+
+	// Incoming messages not marked as read, per chat
+	var unreadCounts []struct {
+		ChatJID string `bun:"chat_jid"`
+		Unread  int    `bun:"unread"`
+	}
+	err = db.NewSelect().
+		Model((*data.Message)(nil)).
+		Column("msg.chat_jid").
+		ColumnExpr("count(*) AS unread").
+		Where("msg.device_id = ?", deviceID).
+		Where(chatMessageCondition).
+		Where("msg.is_from_me = false AND msg.read_at IS NULL AND msg.revoked_at IS NULL").
+		Group("msg.chat_jid").
+		Scan(ctx, &unreadCounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages for device ID %d: %v", deviceID, err)
+	}
+
+	unreadByChat := make(map[string]int, len(unreadCounts))
+	for _, count := range unreadCounts {
+		unreadByChat[count.ChatJID] = count.Unread
+	}
+
+	chats := make([]data.Chat, 0, len(lastMessages))
+	for i := range lastMessages {
+		chats = append(chats, data.Chat{
+			ChatJID:     lastMessages[i].ChatJID,
+			IsGroup:     lastMessages[i].IsFromGroup,
+			UnreadCount: unreadByChat[lastMessages[i].ChatJID],
+			LastMessage: lastMessages[i].ToStoredMessage(),
+		})
+	}
+
+	// Most recent chats first
+	sort.Slice(chats, func(i, j int) bool {
+		return chats[i].LastMessage.Timestamp.After(chats[j].LastMessage.Timestamp)
+	})
+	return chats, nil
+}
+
+// GetChatMessages retrieves the messages of a chat of the device, newest first, applying the filter.
+func GetChatMessages(deviceID int, chatJID string, filter data.MessageFilter) ([]data.Message, error) {
+	db := GetBunConnection()
+
+	var messages []data.Message
+	query := db.NewSelect().
+		Model(&messages).
+		Where("msg.device_id = ? AND msg.chat_jid = ?", deviceID, chatJID).
+		Where(chatMessageCondition)
+
+	if filter.MediaType != "" {
+		query = query.Where("msg.media_type = ?", filter.MediaType)
+	}
+	if filter.IsFromMe != nil {
+		query = query.Where("msg.is_from_me = ?", *filter.IsFromMe)
+	}
+	if filter.Before != nil {
+		query = query.Where("(msg.timestamp, msg.id) < (?, ?)", filter.Before.Timestamp, filter.Before.ID)
+	}
+
+	err := query.
+		OrderExpr("msg.timestamp DESC, msg.id DESC").
+		Limit(filter.Limit).
+		Scan(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve messages of chat %s for device ID %d: %v", chatJID, deviceID, err)
+	}
+	return messages, nil
+}
+
+// MarkMessagesRead marks incoming messages of a chat of the device as read. Messages already read keep their original read time.
+func MarkMessagesRead(deviceID int, chatJID string, messageIDs []string, readAt time.Time) error {
+	db := GetBunConnection()
+
+	_, err := db.NewUpdate().
+		Model((*data.Message)(nil)).
+		Set("read_at = ?", readAt).
+		Where("device_id = ? AND chat_jid = ?", deviceID, chatJID).
+		Where("message_id IN (?)", bun.In(messageIDs)).
+		Where("is_from_me = false AND read_at IS NULL").
+		Exec(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to mark messages of chat %s as read for device ID %d: %v", chatJID, deviceID, err)
+	}
+	return nil
+}
+
+// messageSearchRow is a message matched by the full-text search, with its snippet and rank.
+type messageSearchRow struct {
+	data.Message `bun:",extend"`
+	Snippet      string  `bun:"snippet,scanonly"`
+	Rank         float64 `bun:"rank,scanonly"`
+}
+
+// SearchMessages searches the text and captions of the messages of the device, most relevant first.
+// Messages deleted for everyone are never returned.
+func SearchMessages(deviceID int, search data.MessageSearch) ([]data.MessageSearchResult, error) {
+	db := GetBunConnection()
+
+	// Parse the query with the configuration each message was indexed with, which may differ from
+	// the current SEARCH_LANGUAGE for messages stored before it changed.
+	tsQuery := bun.SafeQuery("websearch_to_tsquery(msg.search_language, ?)", search.Query)
+
+	var rows []messageSearchRow
+	query := db.NewSelect().
+		Model(&rows).
+		ColumnExpr("?TableColumns").
+		ColumnExpr("ts_headline(msg.search_language, msg.text, ?, ?) AS snippet", tsQuery, searchHeadlineOptions).
+		ColumnExpr("ts_rank(msg.search_vector, ?) AS rank", tsQuery).
+		Where("msg.device_id = ?", deviceID).
+		Where(chatMessageCondition).
+		Where("msg.revoked_at IS NULL").
+		Where("msg.search_vector @@ ?", tsQuery)
+
+	if search.ChatJID != "" {
+		query = query.Where("msg.chat_jid = ?", search.ChatJID)
+	}
+	if search.SenderJID != "" {
+		query = query.Where("msg.sender_jid = ?", search.SenderJID)
+	}
+	if search.MediaType != "" {
+		query = query.Where("msg.media_type = ?", search.MediaType)
+	}
+	if search.Since != nil {
+		query = query.Where("msg.timestamp >= ?", *search.Since)
+	}
+	if search.Until != nil {
+		query = query.Where("msg.timestamp < ?", *search.Until)
+	}
+
+	err := query.
+		OrderExpr("rank DESC, msg.timestamp DESC, msg.id DESC").
+		Limit(search.Limit).
+		Offset(search.Offset).
+		Scan(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages for device ID %d: %v", deviceID, err)
+	}
+
+	results := make([]data.MessageSearchResult, 0, len(rows))
+	for i := range rows {
+		results = append(results, data.MessageSearchResult{
+			Message: rows[i].ToStoredMessage(),
+			Snippet: rows[i].Snippet,
+			Rank:    rows[i].Rank,
+		})
+	}
+	return results, nil
+}
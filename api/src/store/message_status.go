@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"whatsgoingon/data"
+)
+
+// UpsertMessageStatus stores the status of a message for a recipient. The row is only updated when the new
+// status is more advanced than the stored one, so late or duplicated receipts are ignored.
+// It returns true when the status was inserted or advanced.
+func UpsertMessageStatus(status *data.MessageStatus) (bool, error) {
+	db := GetBunConnection()
+
+	res, err := db.NewInsert().
+		Model(status).
+		On("CONFLICT (device_id, message_id, recipient_jid) DO UPDATE").
+		Set("status = EXCLUDED.status").
+		Set("status_rank = EXCLUDED.status_rank").
+		Set("sent_at = COALESCE(msg_st.sent_at, EXCLUDED.sent_at)").
+		Set("delivered_at = COALESCE(msg_st.delivered_at, EXCLUDED.delivered_at)").
+		Set("read_at = COALESCE(msg_st.read_at, EXCLUDED.read_at)").
+		Set("played_at = COALESCE(msg_st.played_at, EXCLUDED.played_at)").
+		Set("updated_at = EXCLUDED.updated_at").
+		Where("msg_st.status_rank < EXCLUDED.status_rank").
+		Exec(context.Background())
+
+	if err != nil {
+		return false, fmt.Errorf("failed to save status of message %s: %v", status.MessageID, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save status of message %s: %v", status.MessageID, err)
+	}
+	return affected > 0, nil
+}
+
+// GetMessageStatuses retrieves the status of a sent message for each of its recipients.
+func GetMessageStatuses(deviceID int, messageID string) ([]data.MessageStatus, error) {
+	db := GetBunConnection()
+
+	var statuses []data.MessageStatus
+	err := db.NewSelect().
+		Model(&statuses).
+		Where("device_id = ? AND message_id = ?", deviceID, messageID).
+		Order("status_rank DESC").
+		Scan(context.Background())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of message %s for device ID %d: %v", messageID, deviceID, err)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"whatsgoingon/data"
+)
+
+// SavePoll stores a poll if it has not been stored yet for the device.
+func SavePoll(poll *data.Poll) error {
+	db := GetBunConnection()
+
+	_, err := db.NewInsert().
+		Model(poll).
+		On("CONFLICT (device_id, message_id) DO NOTHING").
+		Exec(context.Background())
+
+	if err != nil {
+		return fmt.Errorf("failed to save poll %s for device ID %d: %v", poll.MessageID, poll.DeviceID, err)
+	}
+	return nil
+}
+
+// GetPollByMessageID retrieves a poll of the device by the ID of its creation message.
+func GetPollByMessageID(deviceID int, messageID string) (data.Poll, error) {
+	db := GetBunConnection()
+
+	poll := new(data.Poll)
+	err := db.NewSelect().
+		Model(poll).
+		Where("device_id = ? AND message_id = ?", deviceID, messageID).
+		Scan(context.Background())
+
+	if err != nil {
+		return data.Poll{}, fmt.Errorf("failed to retrieve poll %s for device ID %d: %v", messageID, deviceID, err)
+	}
+	return *poll, nil
+}
+
+// SavePollVote stores the vote of a voter, replacing their previous vote in the same poll.
+func SavePollVote(vote *data.PollVote) error {
+	db := GetBunConnection()
+
+	_, err := db.NewInsert().
+		Model(vote).
+		On("CONFLICT (poll_id, voter_jid) DO UPDATE").
+		Set("selected_options = EXCLUDED.selected_options").
+		Set("voted_at = EXCLUDED.voted_at").
+		Exec(context.Background())
+
+	if err != nil {
+		return fmt.Errorf("failed to save vote of %s for poll ID %d: %v", vote.VoterJID, vote.PollID, err)
+	}
+	return nil
+}
+
+// GetPollVotes retrieves the latest vote of every voter of a poll.
+func GetPollVotes(pollID int) ([]data.PollVote, error) {
+	db := GetBunConnection()
+
+	var votes []data.PollVote
+	err := db.NewSelect().
+		Model(&votes).
+		Where("poll_id = ?", pollID).
+		Order("voted_at ASC").
+		Scan(context.Background())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve votes for poll ID %d: %v", pollID, err)
+	}
+	return votes, nil
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"go.mau.fi/whatsmeow/store/sqlstore"
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsgoingon/handler"
+)
+
+var (
+	containerOnce     sync.Once           // Ensures that the WhatsMeow container is created only once (singleton).
+	containerInstance *sqlstore.Container // Global WhatsMeow device store container shared by every client.
+	containerErr      error               // Error raised while creating the container, if any.
+	dbLog             = waLog.Stdout("Database", "WARN", true)
+)
+
+// newWhatsmeowContainer opens the WhatsMeow device store on the PostgreSQL schema configured by PG_WM_SCHEMA.
+func newWhatsmeowContainer() (*sqlstore.Container, error) {
+	// Fetch database configuration from environment variables.
+	dbUser := os.Getenv("PG_USERNAME")
+	dbPwd := os.Getenv("PG_PASSWORD")
+	dbTCPHost := os.Getenv("PG_HOSTNAME")
+	dbPort := os.Getenv("PG_PORT")
+	dbName := os.Getenv("PG_DATABASE")
+	dbSchema := os.Getenv("PG_WM_SCHEMA")
+
+	dbURI := fmt.Sprintf("host=%s user=%s password=%s port=%s database=%s search_path=%s sslmode=disable",
+		dbTCPHost, dbUser, dbPwd, dbPort, dbName, dbSchema)
+
+	container, err := sqlstore.New("pgx", dbURI, dbLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WhatsMeow store: %v", err)
+	}
+	return container, nil
+}
+
+// GetWhatsmeowContainer returns the singleton WhatsMeow sqlstore.Container.
+// The container (and its connection pool) is created once and reused by every device lookup and client.
+func GetWhatsmeowContainer() (*sqlstore.Container, error) {
+	containerOnce.Do(func() {
+		containerInstance, containerErr = newWhatsmeowContainer()
+		if containerErr != nil {
+			handler.FailOnError(containerErr, "Failed to connect to the WhatsMeow store")
+		}
+	})
+	return containerInstance, containerErr
+}
+
+// InitConnections opens the Bun connection and the WhatsMeow container at application startup.
+// It returns an error if either of them could not be established.
+func InitConnections() error {
+	if db := GetBunConnection(); db == nil || db.DB == nil {
+		return errors.New("failed to initialise the PostgreSQL connection")
+	}
+	if _, err := GetWhatsmeowContainer(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CloseConnections closes the WhatsMeow container and the Bun connection during application shutdown.
+func CloseConnections() {
+	if containerInstance != nil {
+		handler.FailOnError(containerInstance.Close(), "Failed to close the WhatsMeow store")
+	}
+	if bunInstance != nil && bunInstance.DB != nil {
+		handler.FailOnError(bunInstance.Close(), "Failed to close the PostgreSQL connection")
+	}
+}
@@ -0,0 +1,49 @@
+-- This migration script creates the tables used to keep track of polls and their votes.
+-- Polls are stored with their options so the SHA-256 hashes carried by encrypted votes can be
+-- mapped back to option names, and each voter keeps only their latest vote.
+-- Version: 2
+-- Author: @caiofabiodearaujo
+-- Date: 2024-10-20
+
+-- Create the sequence for the 'poll' table if it does not exist
+create sequence if not exists uatzapi.poll_id_seq;
+
+-- Table 'poll'
+-- This table stores the polls sent or received by each device, including the poll question,
+-- the option names and the message secret needed to decrypt votes.
+create table if not exists uatzapi.poll (
+  id bigint primary key not null default nextval('uatzapi.poll_id_seq'::regclass),
+  device_id bigint not null, -- Foreign key referencing the 'device' table
+  message_id character varying not null, -- WhatsApp ID of the poll creation message
+  chat_jid character varying not null, -- JID of the chat where the poll was sent
+  sender_jid character varying not null, -- JID of the poll author
+  name character varying not null, -- The poll question
+  options character varying[] not null, -- Names of the poll options, in order
+  selectable_count integer not null default 0, -- Maximum number of options a voter can select (0 means unlimited)
+  message_secret bytea, -- Secret used to encrypt and decrypt the votes
+  created_at timestamp with time zone not null default now(), -- Timestamp when the poll was created
+  constraint fk_poll_device_id foreign key (device_id) references uatzapi.device (id) -- Foreign key constraint
+);
+
+-- Creating a unique index on 'device_id' and 'message_id' so a poll is stored only once per device
+create unique index if not exists poll_device_id_message_id_key
+on uatzapi.poll using btree (device_id, message_id);
+
+-- Create the sequence for the 'poll_vote' table if it does not exist
+create sequence if not exists uatzapi.poll_vote_id_seq;
+
+-- Table 'poll_vote'
+-- This table stores the latest vote of each voter in a poll. A new vote from the same voter
+-- replaces the previous one, as WhatsApp votes always carry the full selection.
+create table if not exists uatzapi.poll_vote (
+  id bigint primary key not null default nextval('uatzapi.poll_vote_id_seq'::regclass),
+  poll_id bigint not null, -- Foreign key referencing the 'poll' table
+  voter_jid character varying not null, -- JID of the voter
+  selected_options character varying[] not null, -- Names of the selected options (empty when the vote was removed)
+  voted_at timestamp with time zone not null default now(), -- Timestamp of the vote
+  constraint fk_poll_vote_poll_id foreign key (poll_id) references uatzapi.poll (id) on delete cascade -- Foreign key constraint
+);
+
+-- Creating a unique index on 'poll_id' and 'voter_jid' to keep a single vote per voter
+create unique index if not exists poll_vote_poll_id_voter_jid_key
+on uatzapi.poll_vote using btree (poll_id, voter_jid);
@@ -0,0 +1,32 @@
+-- This migration script creates the table used to track the delivery status of sent messages.
+-- Each message keeps one row per recipient (the chat for direct messages, each participant in groups)
+-- and its status only moves forward: sent -> delivered -> read -> played.
+-- Version: 3
+-- Author: @caiofabiodearaujo
+-- Date: 2024-10-24
+
+-- Create the sequence for the 'message_status' table if it does not exist
+create sequence if not exists uatzapi.message_status_id_seq;
+
+-- Table 'message_status'
+-- This table stores the latest status of each sent message per recipient, along with the time
+-- each stage was reached.
+create table if not exists uatzapi.message_status (
+  id bigint primary key not null default nextval('uatzapi.message_status_id_seq'::regclass),
+  device_id bigint not null, -- Foreign key referencing the 'device' table
+  message_id character varying not null, -- WhatsApp ID of the sent message
+  chat_jid character varying not null, -- JID of the chat the message was sent to
+  recipient_jid character varying not null, -- JID of the recipient that reported the status
+  status character varying not null, -- Latest status (SENT, DELIVERED, READ or PLAYED)
+  status_rank smallint not null, -- Order of the status, used to ignore out-of-order receipts
+  sent_at timestamp with time zone, -- Timestamp when the message was sent
+  delivered_at timestamp with time zone, -- Timestamp when the message was delivered
+  read_at timestamp with time zone, -- Timestamp when the message was read
+  played_at timestamp with time zone, -- Timestamp when the voice note or video was played
+  updated_at timestamp with time zone not null default now(), -- Timestamp of the latest status change
+  constraint fk_message_status_device_id foreign key (device_id) references uatzapi.device (id) -- Foreign key constraint
+);
+
+-- Creating a unique index to keep a single status row per message and recipient
+create unique index if not exists message_status_device_id_message_id_recipient_jid_key
+on uatzapi.message_status using btree (device_id, message_id, recipient_jid);
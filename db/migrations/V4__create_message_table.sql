@@ -0,0 +1,47 @@
+-- This migration script creates the table that keeps the history of every message received or sent
+-- by the devices. Media content is not stored here, only its type and mime type.
+-- Version: 4
+-- Author: @caiofabiodearaujo
+-- Date: 2024-10-28
+
+-- Create the sequence for the 'message' table if it does not exist
+create sequence if not exists uatzapi.message_id_seq;
+
+-- Table 'message'
+-- This table stores each message (and message event such as edits, revokes, reactions and poll votes)
+-- received or sent by a device. A message is stored only once per device and chat.
+create table if not exists uatzapi.message (
+  id bigint primary key not null default nextval('uatzapi.message_id_seq'::regclass),
+  device_id bigint not null, -- Foreign key referencing the 'device' table
+  message_id character varying not null, -- WhatsApp ID of the message
+  chat_jid character varying not null, -- JID of the chat (user or group)
+  sender_jid character varying not null, -- JID of the message author
+  recipient_id character varying not null, -- WhatsApp ID (user part) of the chat
+  push_name character varying not null default '', -- Display name of the author
+  event_type character varying not null, -- MESSAGE, MESSAGE_EDIT, MESSAGE_REVOKE or POLL_VOTE
+  media_type character varying not null, -- TEXT, IMAGE, VIDEO, AUDIO, DOCUMENT, LOCATION, ...
+  text text not null default '', -- Text or caption of the message
+  content_mime_type character varying not null default '', -- Mime type of the message content
+  is_from_me boolean not null, -- Whether the message was sent by the device
+  is_from_group boolean not null, -- Whether the message belongs to a group chat
+  target_message_id character varying, -- ID of the message a reaction, edit, revoke or vote refers to
+  mentioned_me boolean not null default false, -- Whether the device was mentioned
+  metadata jsonb, -- Structured content (location, contacts, poll, poll vote, mentions)
+  timestamp timestamp with time zone not null, -- Timestamp of the message
+  edited_at timestamp with time zone, -- Timestamp of the latest edit of the message
+  revoked_at timestamp with time zone, -- Timestamp when the message was deleted for everyone
+  created_at timestamp with time zone not null default now(), -- Timestamp when the record was created
+  constraint fk_message_device_id foreign key (device_id) references uatzapi.device (id) -- Foreign key constraint
+);
+
+-- Creating a unique index to store each message only once per device and chat
+create unique index if not exists message_device_id_chat_jid_message_id_key
+on uatzapi.message using btree (device_id, chat_jid, message_id);
+
+-- Creating an index on 'device_id' and 'message_id' to look up messages by ID
+create index if not exists message_device_id_message_id_idx
+on uatzapi.message (device_id, message_id);
+
+-- Creating an index on 'device_id', 'chat_jid' and 'timestamp' to read the history of a chat
+create index if not exists message_device_id_chat_jid_timestamp_idx
+on uatzapi.message (device_id, chat_jid, timestamp desc);
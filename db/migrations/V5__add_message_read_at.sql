@@ -0,0 +1,14 @@
+-- This migration script adds the read state to the message history, so chats can report
+-- how many incoming messages were not read yet.
+-- Version: 5
+-- Author: @caiofabiodearaujo
+-- Date: 2024-10-30
+
+-- Add the 'read_at' column to the 'message' table
+alter table uatzapi.message
+add column if not exists read_at timestamp with time zone; -- Timestamp when the incoming message was marked as read
+
+-- Creating a partial index on 'device_id' and 'chat_jid' to count the unread messages of each chat
+create index if not exists message_device_id_chat_jid_unread_idx
+on uatzapi.message (device_id, chat_jid)
+where read_at is null and is_from_me = false;
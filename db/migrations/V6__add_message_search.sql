@@ -0,0 +1,19 @@
+-- This migration script adds full-text search over the text and captions of the message history.
+-- Each message keeps the text search configuration (language) it was indexed with, so stemming
+-- and stop words match the language of the conversation.
+-- Version: 6
+-- Author: @caiofabiodearaujo
+-- Date: 2024-11-02
+
+-- Add the 'search_language' column to the 'message' table
+alter table uatzapi.message
+add column if not exists search_language regconfig not null default 'portuguese'; -- Text search configuration used to index the message
+
+-- Add the generated 'search_vector' column, kept up to date when the text is edited
+alter table uatzapi.message
+add column if not exists search_vector tsvector
+generated always as (to_tsvector(search_language, text)) stored; -- Lexemes of the text or caption of the message
+
+-- Creating a GIN index on 'search_vector' to search messages by text
+create index if not exists message_search_vector_idx
+on uatzapi.message using gin (search_vector);
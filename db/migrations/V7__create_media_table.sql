@@ -0,0 +1,32 @@
+-- This migration script creates the table that indexes the media downloaded from messages.
+-- The content itself lives in the configured media store (local filesystem or S3-compatible bucket)
+-- and is written once: messages carrying the same file share the same media record.
+-- Version: 7
+-- Author: @caiofabiodearaujo
+-- Date: 2024-11-05
+
+-- Create the sequence for the 'media' table if it does not exist
+create sequence if not exists uatzapi.media_id_seq;
+
+-- Table 'media'
+-- This table stores one row per distinct media content, identified by its SHA-256 digest.
+create table if not exists uatzapi.media (
+  id bigint primary key not null default nextval('uatzapi.media_id_seq'::regclass),
+  sha256 character varying not null, -- Hex SHA-256 digest of the content
+  size bigint not null, -- Size of the content in bytes
+  mime_type character varying not null default '', -- Mime type of the content
+  storage_key character varying not null, -- Key of the content in the media store
+  created_at timestamp with time zone not null default now() -- Timestamp when the media was stored
+);
+
+-- Creating a unique index to store each content only once
+create unique index if not exists media_sha256_key
+on uatzapi.media using btree (sha256);
+
+-- Add the 'media_id' column to the 'message' table
+alter table uatzapi.message
+add column if not exists media_id bigint; -- Media of the message, if any
+
+-- Adding the foreign key constraint to the 'media_id' column
+alter table uatzapi.message
+add constraint fk_message_media_id foreign key (media_id) references uatzapi.media (id);